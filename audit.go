@@ -0,0 +1,68 @@
+package censor
+
+import (
+	"reflect"
+
+	"github.com/vpakhuchyi/censor/internal/encoder"
+)
+
+// Reasons of masking reported with MaskEvent.
+const (
+	// MaskReasonStructDefault means that a struct field without a censor tag got the default field action.
+	MaskReasonStructDefault = string(encoder.ReasonStructDefault)
+	// MaskReasonStructTag means that a struct field was masked according to its censor tag.
+	MaskReasonStructTag = string(encoder.ReasonStructTag)
+	// MaskReasonFieldRule means that a struct field was masked according to a field rule.
+	MaskReasonFieldRule = string(encoder.ReasonFieldRule)
+	// MaskReasonMapKey means that a map value was masked as its key is matched by the mask map keys patterns.
+	MaskReasonMapKey = string(encoder.ReasonMapKey)
+	// MaskReasonDenyByDefault means that a value was masked in the deny-by-default mode.
+	MaskReasonDenyByDefault = string(encoder.ReasonDenyByDefault)
+	// MaskReasonExcludePattern means that a part of a string was matched by the exclude patterns.
+	MaskReasonExcludePattern = string(encoder.ReasonExcludePattern)
+	// MaskReasonNamedPattern means that a part of a string was matched by a named exclude pattern.
+	MaskReasonNamedPattern = string(encoder.ReasonNamedPattern)
+	// MaskReasonDetector means that a part of a string was found by a built-in detector or the entropy detector.
+	MaskReasonDetector = string(encoder.ReasonDetector)
+	// MaskReasonSecret means that a string contains a known secret, see Processor.RegisterSecrets.
+	MaskReasonSecret = string(encoder.ReasonSecret)
+	// MaskReasonUnsupportedType means that a value of an unsupported type was replaced with a placeholder.
+	MaskReasonUnsupportedType = string(encoder.ReasonUnsupportedType)
+)
+
+// MaskEvent describes a value that was masked, hashed, partially masked or omitted, see WithOnMask.
+// It never contains the value itself.
+type MaskEvent struct {
+	// Path is the path of the value, e.g. "User.Address.Street". It starts with the name of the encoded struct type,
+	// struct fields are referred to by their Go names, and elements of slices, arrays and maps are denoted with "[]",
+	// so map keys never get into the path. Members of embedded JSON documents are referred to by their keys.
+	Path string
+	// Reason describes why the value was masked, e.g. MaskReasonStructDefault.
+	Reason string
+	// Rule identifies the rule that caused the masking: the path of a field rule, the name of a named exclude
+	// pattern or the name of a detector ("entropy" for the entropy detector). It's empty for other reasons.
+	Rule string
+	// Type is the type of the value. For parts of strings matched by patterns, detectors or known secrets,
+	// it's always the string type. It's nil for members of embedded JSON documents masked by their keys.
+	Type reflect.Type
+	// Format is the output format: "text" or "json".
+	Format string
+}
+
+// encoderHook returns a hook of the encoder that passes events of the given output format to fn.
+// It returns nil if fn is nil, so encoders don't track paths at all.
+func encoderHook(fn func(MaskEvent), format string) func(encoder.MaskEvent) {
+	if fn == nil {
+		return nil
+	}
+
+	return func(e encoder.MaskEvent) {
+		fn(MaskEvent{
+			Path:   e.Path,
+			Reason: string(e.Reason),
+			Rule:   e.Rule,
+			Type:   e.Type,
+			Format: format,
+		})
+	}
+}
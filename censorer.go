@@ -0,0 +1,20 @@
+package censor
+
+// Censorer is implemented by types that control their own masked representation.
+//
+// If a value implements Censorer, the value returned by CensorValue is encoded instead of it.
+// Unlike json.Marshaler, the returned value is encoded using the regular rules, so returned structs
+// are still masked according to their tags and strings are checked against the exclude patterns.
+// If CensorValue returns a value of the same type, it's encoded without calling CensorValue again.
+//
+// Example:
+//
+//	func (r PaymentRequest) CensorValue() any {
+//		return struct {
+//			ID     string `censor:"display"`
+//			Amount int    `censor:"display"`
+//		}{ID: r.ID, Amount: r.Amount}
+//	}
+type Censorer interface {
+	CensorValue() any
+}
@@ -0,0 +1,26 @@
+package censortest
+
+import (
+	"strings"
+	"testing"
+)
+
+// AssertNoLeak reports a test error for every given value that was written to the recorder. Empty values are ignored.
+// It returns true if none of the values was written.
+//
+// The leaked values are included in the error messages, so only test values (e.g. canaries, see Fill)
+// must be passed to it, never real secrets.
+func AssertNoLeak(t testing.TB, r *Recorder, secrets ...string) bool {
+	t.Helper()
+
+	output := r.String()
+	ok := true
+	for _, s := range secrets {
+		if s != "" && strings.Contains(output, s) {
+			t.Errorf("censortest: value %q leaked to the output", s)
+			ok = false
+		}
+	}
+
+	return ok
+}
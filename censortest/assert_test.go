@@ -0,0 +1,57 @@
+package censortest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT records errors reported with it instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNoLeak(t *testing.T) {
+	tests := map[string]struct {
+		output  string
+		secrets []string
+		exp     []string
+	}{
+		"no_leaks": {
+			output:  `{"msg":"login","password":"[CENSORED]"}`,
+			secrets: []string{"hunter2", ""},
+		},
+		"leaks": {
+			output:  `{"msg":"login","password":"hunter2","token":"t0ken"}`,
+			secrets: []string{"hunter2", "secret", "t0ken"},
+			exp: []string{
+				`censortest: value "hunter2" leaked to the output`,
+				`censortest: value "t0ken" leaked to the output`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			r := NewRecorder()
+			_, _ = r.Write([]byte(tt.output))
+			rt := &recordingT{TB: t}
+
+			// WHEN.
+			got := AssertNoLeak(rt, r, tt.secrets...)
+
+			// THEN.
+			require.Equal(t, tt.exp == nil, got)
+			require.Equal(t, tt.exp, rt.errors)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package censortest
+
+/*
+Package censortest provides helpers for tests that check that sensitive values never reach the logs. It's designed
+for a one-liner test that logs a fully populated value through the real logger setup and fails if any value that
+must be hidden appears in the output.
+
+Typical workflow:
+
+	import (
+		"log/slog"
+		"testing"
+
+		"github.com/vpakhuchyi/censor"
+		"github.com/vpakhuchyi/censor/censortest"
+		sloghandler "github.com/vpakhuchyi/censor/handlers/slog"
+	)
+
+	func TestPaymentRequestLogging(t *testing.T) {
+		p := censor.New()
+		rec := censortest.NewRecorder()
+		logger := slog.New(sloghandler.NewJSONHandler(sloghandler.WithCensor(p), sloghandler.WithOut(rec)))
+
+		var req PaymentRequest
+		canaries := censortest.Fill(&req)
+
+		logger.Info("payment requested", slog.Any("request", req))
+
+		censortest.AssertNoLeak(t, rec, canaries.Hidden(p, req)...)
+	}
+
+Important considerations:
+
+  - Fill puts unique canary values only into string and byte slice fields, including the ones of nested structs,
+    pointers, slices, arrays and maps. Other fields keep their zero values.
+  - Canaries.Hidden uses censor.Processor.Inspect to find the canaries that must not appear in the output,
+    so the same processor that is used by the logger must be passed to it.
+  - Recorder is safe for concurrent use and implements zapcore.WriteSyncer, so it can be used with zapcore.AddSync
+    or passed to zap cores and zerolog loggers directly.
+  - AssertNoLeak reports leaked values in the test output, so pass only test values to it, never real secrets.
+
+Supported helpers:
+
+  - NewRecorder() — an io.Writer that keeps everything written to it in memory.
+  - Fill(ptr) — fills the struct pointed to by ptr with unique canary values.
+  - Canaries.Hidden(p, v) — returns the canaries that p must hide when v is encoded.
+  - AssertNoLeak(t, recorder, secrets...) — fails the test if any of the values was written to the recorder.
+*/
@@ -0,0 +1,158 @@
+package censortest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/vpakhuchyi/censor"
+)
+
+// canaryPrefixLength is the length of the random part shared by the canaries of a single Fill call.
+const canaryPrefixLength = 8
+
+// Canary is a unique value put into a field by Fill.
+type Canary struct {
+	// Path is the path of the field in the same form as censor.ReportEntry.Path, e.g. "User.Address.Street".
+	Path string
+	// Value is the canary itself, e.g. "canary-K7QX2M4P-00000003".
+	Value string
+}
+
+// Canaries contains the canaries put into a value by Fill.
+type Canaries []Canary
+
+// Values returns the values of all the canaries.
+func (c Canaries) Values() []string {
+	values := make([]string, 0, len(c))
+	for _, canary := range c {
+		values = append(values, canary.Value)
+	}
+
+	return values
+}
+
+// Hidden returns the values of the canaries that p must hide when v is encoded. Canaries of fields that are displayed,
+// including strings with parts masked by patterns, are not returned. See censor.Processor.Inspect.
+func (c Canaries) Hidden(p *censor.Processor, v any) []string {
+	visible := make(map[string]bool)
+	for _, e := range p.Inspect(v).Entries {
+		if e.Decision == censor.DecisionDisplayed || e.Decision == censor.DecisionMaskedByPattern {
+			visible[e.Path] = true
+		}
+	}
+
+	var hidden []string
+	for _, canary := range c {
+		if !visible[canary.Path] {
+			hidden = append(hidden, canary.Value)
+		}
+	}
+
+	return hidden
+}
+
+// Fill fills the exported fields of the struct pointed to by ptr with unique canary values and returns them.
+// Strings and byte slices get canaries, nil pointers, slices and maps with string keys get a single element
+// that is filled the same way, and all the elements of arrays are filled. Other fields are left untouched.
+// Recursive types are filled only once on every path.
+//
+// Note: this function panics if ptr is not a non-nil pointer to a struct.
+func Fill(ptr any) Canaries {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		panic("censortest: Fill requires a non-nil pointer to a struct")
+	}
+
+	f := filler{prefix: rand.Text()[:canaryPrefixLength]}
+	f.fill(v.Elem(), "")
+
+	return f.canaries
+}
+
+// filler holds the state of a single Fill call.
+type filler struct {
+	prefix   string
+	canaries Canaries
+	// types contains the struct types on the current path, so values of recursive types are finite.
+	types []reflect.Type
+}
+
+// fill fills the given settable value at the given path.
+//
+//nolint:exhaustive
+func (f *filler) fill(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(f.canary(path))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(f.canary(path)))
+
+			return
+		}
+
+		s := reflect.MakeSlice(v.Type(), 1, 1)
+		f.fill(s.Index(0), path+"[]")
+		v.Set(s)
+	case reflect.Array:
+		for i := range v.Len() {
+			f.fill(v.Index(i), path+"[]")
+		}
+	case reflect.Pointer:
+		if f.recursive(v.Type().Elem()) {
+			return
+		}
+
+		p := reflect.New(v.Type().Elem())
+		f.fill(p.Elem(), path)
+		v.Set(p)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || f.recursive(v.Type().Elem()) {
+			return
+		}
+
+		m := reflect.MakeMap(v.Type())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		f.fill(elem, path+"[]")
+		m.SetMapIndex(reflect.ValueOf("key").Convert(v.Type().Key()), elem)
+		v.Set(m)
+	case reflect.Struct:
+		f.fillStruct(v, path)
+	}
+}
+
+// fillStruct fills the exported fields of the given struct. The path of the encoded struct itself
+// starts with its type name.
+func (f *filler) fillStruct(v reflect.Value, path string) {
+	t := v.Type()
+	if f.recursive(t) {
+		return
+	}
+
+	if path == "" {
+		path = t.Name()
+	}
+
+	f.types = append(f.types, t)
+	for i := range t.NumField() {
+		if field := t.Field(i); field.IsExported() {
+			f.fill(v.Field(i), path+"."+field.Name)
+		}
+	}
+	f.types = f.types[:len(f.types)-1]
+}
+
+// recursive reports whether the given type is a struct type that is already on the current path.
+func (f *filler) recursive(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && slices.Contains(f.types, t)
+}
+
+// canary returns a new canary for the given path. The number is zero-padded, so no canary is a part of another one.
+func (f *filler) canary(path string) string {
+	value := fmt.Sprintf("canary-%s-%08d", f.prefix, len(f.canaries)+1)
+	f.canaries = append(f.canaries, Canary{Path: path, Value: value})
+
+	return value
+}
@@ -0,0 +1,121 @@
+package censortest
+
+import (
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpakhuchyi/censor"
+	sloghandler "github.com/vpakhuchyi/censor/handlers/slog"
+)
+
+type card struct {
+	Holder string `censor:"display"`
+	PAN    string
+	CVV    []byte
+}
+
+type node struct {
+	Name string `censor:"display"`
+	Next *node  `censor:"display"`
+}
+
+type paymentRequest struct {
+	ID       string `censor:"display"`
+	Amount   int    `censor:"display"`
+	Card     card   `censor:"display"`
+	Backup   *card
+	Notes    []string          `censor:"display"`
+	Codes    [2]string         `censor:"display"`
+	Meta     map[string]string `censor:"display"`
+	Chain    node              `censor:"display"`
+	internal string
+}
+
+func TestFill(t *testing.T) {
+	// GIVEN.
+	var req paymentRequest
+
+	// WHEN.
+	got := Fill(&req)
+
+	// THEN.
+	paths := make([]string, 0, len(got))
+	for _, c := range got {
+		require.Regexp(t, regexp.MustCompile(`^canary-[A-Z2-7]{8}-\d{8}$`), c.Value)
+		paths = append(paths, c.Path)
+	}
+	require.Equal(t, []string{
+		"paymentRequest.ID",
+		"paymentRequest.Card.Holder",
+		"paymentRequest.Card.PAN",
+		"paymentRequest.Card.CVV",
+		"paymentRequest.Backup.Holder",
+		"paymentRequest.Backup.PAN",
+		"paymentRequest.Backup.CVV",
+		"paymentRequest.Notes[]",
+		"paymentRequest.Codes[]",
+		"paymentRequest.Codes[]",
+		"paymentRequest.Meta[]",
+		"paymentRequest.Chain.Name",
+	}, paths)
+	require.ElementsMatch(t, got.Values(), []string{
+		req.ID, req.Card.Holder, req.Card.PAN, string(req.Card.CVV), req.Backup.Holder, req.Backup.PAN,
+		string(req.Backup.CVV), req.Notes[0], req.Codes[0], req.Codes[1], req.Meta["key"], req.Chain.Name,
+	})
+	require.Zero(t, req.Amount)
+	require.Nil(t, req.Chain.Next)
+	require.Empty(t, req.internal)
+}
+
+func TestFill_InvalidValue(t *testing.T) {
+	for name, v := range map[string]any{
+		"not_pointer":    paymentRequest{},
+		"nil_pointer":    (*paymentRequest)(nil),
+		"not_struct_ptr": new(string),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.PanicsWithValue(t, "censortest: Fill requires a non-nil pointer to a struct", func() {
+				Fill(v)
+			})
+		})
+	}
+}
+
+func TestCanaries_Hidden(t *testing.T) {
+	// GIVEN.
+	p := censor.New()
+	var req paymentRequest
+	canaries := Fill(&req)
+
+	// WHEN.
+	got := canaries.Hidden(p, req)
+
+	// THEN.
+	require.ElementsMatch(t, []string{
+		req.Card.PAN, string(req.Card.CVV), req.Backup.Holder, req.Backup.PAN, string(req.Backup.CVV),
+	}, got)
+}
+
+func TestAssertNoLeak_Logger(t *testing.T) {
+	// GIVEN.
+	p := censor.New()
+	rec := NewRecorder()
+	logger := slog.New(sloghandler.NewJSONHandler(sloghandler.WithCensor(p), sloghandler.WithOut(rec)))
+
+	var req paymentRequest
+	canaries := Fill(&req)
+
+	// WHEN.
+	logger.Info("payment requested", slog.Any("request", req))
+
+	// THEN.
+	require.True(t, AssertNoLeak(t, rec, canaries.Hidden(p, req)...))
+	require.Contains(t, rec.String(), req.Card.Holder)
+
+	rt := &recordingT{TB: t}
+	require.False(t, AssertNoLeak(rt, rec, canaries.Values()...))
+	require.Len(t, rt.errors, len(canaries)-5)
+}
@@ -0,0 +1,47 @@
+package censortest
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Recorder is an io.Writer that keeps everything written to it in memory, e.g. the output of a logger.
+// It's safe for concurrent use.
+type Recorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewRecorder returns a new empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Write appends p to the recorded output. It never returns an error.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.buf.Write(p)
+}
+
+// Sync does nothing. It makes Recorder usable as zapcore.WriteSyncer.
+func (r *Recorder) Sync() error {
+	return nil
+}
+
+// String returns a copy of the recorded output.
+func (r *Recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.buf.String()
+}
+
+// Reset discards the recorded output.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf.Reset()
+}
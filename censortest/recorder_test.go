@@ -0,0 +1,33 @@
+package censortest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	// GIVEN.
+	r := NewRecorder()
+
+	// WHEN.
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = fmt.Fprintf(r, "line %d\n", i)
+		}()
+	}
+	wg.Wait()
+
+	// THEN.
+	require.Len(t, r.String(), 70)
+	require.Contains(t, r.String(), "line 7\n")
+	require.NoError(t, r.Sync())
+
+	r.Reset()
+	require.Empty(t, r.String())
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const censorPkgPath = "github.com/vpakhuchyi/censor"
+
+// writerMethods contains JSONStructWriter methods used to write values of predeclared types without reflection.
+var writerMethods = map[types.BasicKind]string{
+	types.Bool:    "Bool",
+	types.Int:     "Int",
+	types.Int8:    "Int8",
+	types.Int16:   "Int16",
+	types.Int32:   "Int32",
+	types.Int64:   "Int64",
+	types.Uint:    "Uint",
+	types.Uint8:   "Uint8",
+	types.Uint16:  "Uint16",
+	types.Uint32:  "Uint32",
+	types.Uint64:  "Uint64",
+	types.Uintptr: "Uintptr",
+	types.Float32: "Float32",
+	types.Float64: "Float64",
+	types.String:  "String",
+}
+
+// generate returns the source code of AppendCensoredJSON methods for the given types of the package in dir.
+// The output file is excluded from the package, so a stale version of it doesn't affect the result.
+func generate(dir string, typeNames []string, output string) ([]byte, error) {
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg.Path() == censorPkgPath {
+		return nil, errors.New("code can't be generated for the censor package itself")
+	}
+
+	var body bytes.Buffer
+	for _, name := range typeNames {
+		name = strings.TrimSpace(name)
+
+		s, err := lookupStruct(pkg, name)
+		if err != nil {
+			return nil, err
+		}
+
+		writeMethod(&body, name, typeName(pkg, name), s)
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by censorgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg.Name())
+	fmt.Fprintf(&src, "import %q\n", censorPkgPath)
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// loadPackage parses and type-checks non-test Go files of the package in dir, except the output file.
+// Type errors are ignored: they are usually caused by code that refers to the methods being generated.
+func loadPackage(dir, output string) (*types.Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(importPath(dir), fset, files, nil)
+
+	return pkg, nil
+}
+
+// importPath returns an import path of the package in dir based on the closest go.mod file.
+// If there is no go.mod file, the package name is used instead.
+func importPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Base(dir)
+	}
+
+	for d := abs; ; d = filepath.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(d, abs)
+			if err != nil {
+				break
+			}
+
+			return filepath.ToSlash(filepath.Join(modulePath(data), rel))
+		}
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	return filepath.Base(abs)
+}
+
+// modulePath returns the module path declared in the given go.mod file content.
+func modulePath(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+
+	return ""
+}
+
+// lookupStruct returns the struct type with the given name declared in the package.
+func lookupStruct(pkg *types.Package, name string) (*types.Struct, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not found in package %s", name, pkg.Name())
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok || obj.IsAlias() {
+		return nil, fmt.Errorf("type %s must be a defined struct type", name)
+	}
+
+	if named.TypeParams().Len() != 0 {
+		return nil, fmt.Errorf("type %s is generic, which is not supported", name)
+	}
+
+	s, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	return s, nil
+}
+
+// typeName returns the name of the type qualified with the package path, the same way it's reported by reflection.
+func typeName(pkg *types.Package, name string) string {
+	if pkg.Name() == "main" {
+		return "main." + name
+	}
+
+	return pkg.Path() + "." + name
+}
+
+// writeMethod writes the AppendCensoredJSON method for the struct type with the given name.
+// Fields are written in the declaration order, the same way they are encoded with reflection.
+func writeMethod(b *bytes.Buffer, name, fullName string, s *types.Struct) {
+	fmt.Fprintf(b, "\n// AppendCensoredJSON implements censor.JSONAppender.\n")
+	fmt.Fprintf(b, "func (v %s) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {\n", name)
+	fmt.Fprintf(b, "w := p.BeginJSONStruct(dst, %q)\n", fullName)
+
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		tag := reflect.StructTag(s.Tag(i))
+		jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+
+		fmt.Fprintf(b, "if w.Field(%q, %q, %q) {\n", field.Name(), jsonName, tag.Get("censor"))
+		fmt.Fprintf(b, "%s\n", valueCall(field))
+		b.WriteString("}\n")
+	}
+
+	b.WriteString("return w.End()\n}\n")
+}
+
+// valueCall returns a JSONStructWriter call that writes the value of the given field.
+func valueCall(field *types.Var) string {
+	ref := "v." + field.Name()
+
+	if types.IsInterface(field.Type()) {
+		// A pointer is passed to preserve the static type of the field.
+		return "w.ValuePtr(&" + ref + ")"
+	}
+
+	if t, ok := types.Unalias(field.Type()).(*types.Basic); ok {
+		if method, ok := writerMethods[t.Kind()]; ok {
+			return "w." + method + "(" + ref + ")"
+		}
+	}
+
+	return "w.Value(" + ref + ")"
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("fixture is up to date", func(t *testing.T) {
+		// GIVEN.
+		dir := filepath.Join("internal", "fixture")
+		exp, err := os.ReadFile(filepath.Join(dir, defaultOutput))
+		require.NoError(t, err)
+
+		// WHEN.
+		got, err := generate(dir, []string{"User", "Address", "Profile", "Secret", "Tree", "Account"}, defaultOutput)
+
+		// THEN.
+		require.NoError(t, err)
+		require.Equal(t, string(exp), string(got), "run go generate ./cmd/censorgen/...")
+	})
+
+	t.Run("output", func(t *testing.T) {
+		// GIVEN.
+		dir := t.TempDir()
+		src := "package sample\n\n" +
+			"type Sample struct {\n" +
+			"\tID    int    `censor:\"display\" json:\"id,omitempty\"`\n" +
+			"\tName  string `json:\"-\"`\n" +
+			"\tTags  []string `censor:\"display\"`\n" +
+			"\tErr   error\n" +
+			"\tA, B  bool\n" +
+			"\tprivate string\n" +
+			"}\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "sample.go"), []byte(src), 0o600))
+		// A stale output file must be ignored.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, defaultOutput), []byte("package sample\n\nbroken"), 0o600))
+
+		// WHEN.
+		got, err := generate(dir, []string{" Sample "}, defaultOutput)
+
+		// THEN.
+		require.NoError(t, err)
+		// The directory isn't a part of a module, so its name is used as the package path.
+		typeName := filepath.Base(dir) + ".Sample"
+		require.Equal(t, `// Code generated by censorgen; DO NOT EDIT.
+
+package sample
+
+import "github.com/vpakhuchyi/censor"
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v Sample) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "`+typeName+`")
+	if w.Field("ID", "id", "display") {
+		w.Int(v.ID)
+	}
+	if w.Field("Name", "-", "") {
+		w.String(v.Name)
+	}
+	if w.Field("Tags", "", "display") {
+		w.Value(v.Tags)
+	}
+	if w.Field("Err", "", "") {
+		w.ValuePtr(&v.Err)
+	}
+	if w.Field("A", "", "") {
+		w.Bool(v.A)
+	}
+	if w.Field("B", "", "") {
+		w.Bool(v.B)
+	}
+	return w.End()
+}
+`, string(got))
+	})
+
+	tests := map[string]struct {
+		dir     string
+		typ     string
+		wantErr string
+	}{
+		"unknown type": {
+			dir:     filepath.Join("testdata", "invalid"),
+			typ:     "Unknown",
+			wantErr: "type Unknown is not found in package invalid",
+		},
+		"not a struct": {
+			dir:     filepath.Join("testdata", "invalid"),
+			typ:     "Status",
+			wantErr: "type Status is not a struct",
+		},
+		"generic type": {
+			dir:     filepath.Join("testdata", "invalid"),
+			typ:     "Page",
+			wantErr: "type Page is generic, which is not supported",
+		},
+		"alias": {
+			dir:     filepath.Join("testdata", "invalid"),
+			typ:     "Alias",
+			wantErr: "type Alias must be a defined struct type",
+		},
+		"no Go files": {
+			dir:     "testdata",
+			typ:     "Status",
+			wantErr: "no Go files found in testdata",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN.
+			got, err := generate(tt.dir, []string{tt.typ}, defaultOutput)
+
+			// THEN.
+			require.EqualError(t, err, tt.wantErr)
+			require.Nil(t, got)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Code generated by censorgen; DO NOT EDIT.
+
+package fixture
+
+import "github.com/vpakhuchyi/censor"
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v User) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.User")
+	if w.Field("Embedded", "", "display") {
+		w.Value(v.Embedded)
+	}
+	if w.Field("ID", "id", "display") {
+		w.Int64(v.ID)
+	}
+	if w.Field("Name", "name", "mask=first2") {
+		w.String(v.Name)
+	}
+	if w.Field("Email", "email", "mask=email") {
+		w.String(v.Email)
+	}
+	if w.Field("Password", "password", "omit") {
+		w.String(v.Password)
+	}
+	if w.Field("Token", "token", "hash") {
+		w.String(v.Token)
+	}
+	if w.Field("Card", "card", "mask=last4") {
+		w.String(v.Card)
+	}
+	if w.Field("Hidden", "-", "display") {
+		w.String(v.Hidden)
+	}
+	if w.Field("Comment", "", "display") {
+		w.String(v.Comment)
+	}
+	if w.Field("Alias", "", "display") {
+		w.String(v.Alias)
+	}
+	if w.Field("Status", "", "display") {
+		w.Value(v.Status)
+	}
+	if w.Field("Active", "", "display") {
+		w.Bool(v.Active)
+	}
+	if w.Field("Age", "", "display") {
+		w.Uint8(v.Age)
+	}
+	if w.Field("Rating", "", "display") {
+		w.Float32(v.Rating)
+	}
+	if w.Field("Balance", "", "display") {
+		w.Float64(v.Balance)
+	}
+	if w.Field("Small", "", "display") {
+		w.Int8(v.Small)
+	}
+	if w.Field("Medium", "", "display") {
+		w.Int16(v.Medium)
+	}
+	if w.Field("Rune", "", "display") {
+		w.Int32(v.Rune)
+	}
+	if w.Field("Big", "", "display") {
+		w.Uint64(v.Big)
+	}
+	if w.Field("Count", "", "display") {
+		w.Int(v.Count)
+	}
+	if w.Field("Ptr", "", "display") {
+		w.Uintptr(v.Ptr)
+	}
+	if w.Field("U", "", "display") {
+		w.Uint(v.U)
+	}
+	if w.Field("U16", "", "display") {
+		w.Uint16(v.U16)
+	}
+	if w.Field("U32", "", "display") {
+		w.Uint32(v.U32)
+	}
+	if w.Field("Bytes", "", "display") {
+		w.Value(v.Bytes)
+	}
+	if w.Field("Tags", "", "display") {
+		w.Value(v.Tags)
+	}
+	if w.Field("Labels", "", "display") {
+		w.Value(v.Labels)
+	}
+	if w.Field("Address", "address", "display") {
+		w.Value(v.Address)
+	}
+	if w.Field("Addresses", "", "display") {
+		w.Value(v.Addresses)
+	}
+	if w.Field("Contact", "", "display") {
+		w.Value(v.Contact)
+	}
+	if w.Field("Profile", "", "display") {
+		w.Value(v.Profile)
+	}
+	if w.Field("Secret", "", "display") {
+		w.Value(v.Secret)
+	}
+	if w.Field("Any", "", "display") {
+		w.ValuePtr(&v.Any)
+	}
+	if w.Field("Err", "", "display") {
+		w.ValuePtr(&v.Err)
+	}
+	if w.Field("Raw", "", "display") {
+		w.Value(v.Raw)
+	}
+	if w.Field("CreatedAt", "", "display") {
+		w.Value(v.CreatedAt)
+	}
+	if w.Field("Untagged", "", "") {
+		w.String(v.Untagged)
+	}
+	if w.Field("Masked", "", "mask") {
+		w.Int(v.Masked)
+	}
+	return w.End()
+}
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v Address) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.Address")
+	if w.Field("City", "city", "display") {
+		w.String(v.City)
+	}
+	if w.Field("Street", "street", "") {
+		w.String(v.Street)
+	}
+	return w.End()
+}
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v Profile) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.Profile")
+	if w.Field("Bio", "", "display") {
+		w.String(v.Bio)
+	}
+	if w.Field("Age", "", "") {
+		w.Int(v.Age)
+	}
+	if w.Field("Next", "", "display") {
+		w.Value(v.Next)
+	}
+	return w.End()
+}
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v Secret) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.Secret")
+	if w.Field("Value", "", "display") {
+		w.String(v.Value)
+	}
+	return w.End()
+}
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v Tree) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.Tree")
+	if w.Field("Name", "", "display") {
+		w.String(v.Name)
+	}
+	if w.Field("Children", "", "display") {
+		w.Value(v.Children)
+	}
+	return w.End()
+}
+
+// AppendCensoredJSON implements censor.JSONAppender.
+func (v Account) AppendCensoredJSON(dst []byte, p *censor.Processor) []byte {
+	w := p.BeginJSONStruct(dst, "github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.Account")
+	if w.Field("ID", "", "display") {
+		w.Int64(v.ID)
+	}
+	if w.Field("Owner", "", "mask=first1") {
+		w.String(v.Owner)
+	}
+	if w.Field("Email", "", "mask=email") {
+		w.String(v.Email)
+	}
+	if w.Field("IBAN", "", "mask=last4") {
+		w.String(v.IBAN)
+	}
+	if w.Field("Balance", "", "display") {
+		w.Float64(v.Balance)
+	}
+	if w.Field("Active", "", "display") {
+		w.Bool(v.Active)
+	}
+	if w.Field("Country", "", "display") {
+		w.String(v.Country)
+	}
+	if w.Field("Token", "", "") {
+		w.String(v.Token)
+	}
+	return w.End()
+}
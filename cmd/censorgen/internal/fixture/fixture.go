@@ -0,0 +1,112 @@
+// Package fixture contains types used to test code generated by censorgen.
+package fixture
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//go:generate go run ../.. -type User,Address,Profile,Secret,Tree,Account
+
+// Status is a named string type.
+type Status string
+
+// Alias is an alias of a predeclared type.
+type Alias = string
+
+// Address is a struct with generated code, which is used as a nested value.
+type Address struct {
+	City   string `censor:"display" json:"city"`
+	Street string `json:"street,omitempty"`
+}
+
+// Contact is a nested struct without generated code.
+type Contact struct {
+	Phone string `censor:"mask=last4"`
+	Email string `censor:"mask=email"`
+}
+
+// Secret implements the censor.Censorer interface, which takes precedence over generated code.
+type Secret struct {
+	Value string `censor:"display"`
+}
+
+// CensorValue implements the censor.Censorer interface.
+func (s Secret) CensorValue() any {
+	return "[SECRET]"
+}
+
+// Embedded is embedded into the User struct.
+type Embedded struct {
+	Note string `censor:"display"`
+}
+
+// User covers most of the field kinds and tags.
+type User struct {
+	Embedded `censor:"display"`
+
+	ID        int64             `censor:"display" json:"id"`
+	Name      string            `censor:"mask=first2" json:"name"`
+	Email     string            `censor:"mask=email" json:"email"`
+	Password  string            `censor:"omit" json:"password"`
+	Token     string            `censor:"hash" json:"token"`
+	Card      string            `censor:"mask=last4" json:"card"`
+	Hidden    string            `censor:"display" json:"-"`
+	Comment   string            `censor:"display" json:",omitempty"`
+	Alias     Alias             `censor:"display"`
+	Status    Status            `censor:"display"`
+	Active    bool              `censor:"display"`
+	Age       uint8             `censor:"display"`
+	Rating    float32           `censor:"display"`
+	Balance   float64           `censor:"display"`
+	Small     int8              `censor:"display"`
+	Medium    int16             `censor:"display"`
+	Rune      rune              `censor:"display"`
+	Big       uint64            `censor:"display"`
+	Count     int               `censor:"display"`
+	Ptr       uintptr           `censor:"display"`
+	U         uint              `censor:"display"`
+	U16       uint16            `censor:"display"`
+	U32       uint32            `censor:"display"`
+	Bytes     []byte            `censor:"display"`
+	Tags      []string          `censor:"display"`
+	Labels    map[string]string `censor:"display"`
+	Address   Address           `censor:"display" json:"address"`
+	Addresses []*Address        `censor:"display"`
+	Contact   Contact           `censor:"display"`
+	Profile   *Profile          `censor:"display"`
+	Secret    Secret            `censor:"display"`
+	Any       any               `censor:"display"`
+	Err       error             `censor:"display"`
+	Raw       json.RawMessage   `censor:"display"`
+	CreatedAt time.Time         `censor:"display"`
+	Untagged  string
+	Masked    int `censor:"mask"`
+
+	private string
+}
+
+// Profile is a recursive type used via a pointer.
+type Profile struct {
+	Bio  string `censor:"display"`
+	Age  int
+	Next *Profile `censor:"display"`
+}
+
+// Tree is a recursive type.
+type Tree struct {
+	Name     string  `censor:"display"`
+	Children []*Tree `censor:"display"`
+}
+
+// Account is a flat struct of predeclared types, which is the most common case for generated code.
+type Account struct {
+	ID      int64   `censor:"display"`
+	Owner   string  `censor:"mask=first1"`
+	Email   string  `censor:"mask=email"`
+	IBAN    string  `censor:"mask=last4"`
+	Balance float64 `censor:"display"`
+	Active  bool    `censor:"display"`
+	Country string  `censor:"display"`
+	Token   string
+}
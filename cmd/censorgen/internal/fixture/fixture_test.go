@@ -0,0 +1,288 @@
+package fixture
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpakhuchyi/censor"
+)
+
+func newUser() User {
+	profile := &Profile{Bio: "bio", Age: 30}
+	profile.Next = &Profile{Bio: "next <bio>"}
+
+	return User{
+		Embedded:  Embedded{Note: "note"},
+		ID:        42,
+		Name:      "Ivan",
+		Email:     "ivan@example.com",
+		Password:  "password",
+		Token:     "token",
+		Card:      "4111111111111234",
+		Hidden:    "hidden",
+		Comment:   "comment \"quoted\"\n",
+		Alias:     "alias",
+		Status:    "active",
+		Active:    true,
+		Age:       30,
+		Rating:    4.7,
+		Balance:   -1234.5678,
+		Small:     -8,
+		Medium:    16,
+		Rune:      'r',
+		Big:       math.MaxUint64,
+		Count:     -1,
+		Ptr:       7,
+		U:         1,
+		U16:       2,
+		U32:       3,
+		Bytes:     []byte("bytes"),
+		Tags:      []string{"a", "b"},
+		Labels:    map[string]string{"key": "value"},
+		Address:   Address{City: "Kharkiv", Street: "Nauky Avenue"},
+		Addresses: []*Address{{City: "Kyiv"}, nil},
+		Contact:   Contact{Phone: "+380501234567", Email: "contact@example.com"},
+		Profile:   profile,
+		Secret:    Secret{Value: "value"},
+		Any:       Address{City: "Lviv"},
+		Err:       errors.New("error"),
+		Raw:       json.RawMessage(`{"raw":true}`),
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Untagged:  "untagged",
+		Masked:    5,
+		private:   "private",
+	}
+}
+
+func newAccount() Account {
+	return Account{
+		ID:      1,
+		Owner:   "Ivan",
+		Email:   "ivan@example.com",
+		IBAN:    "UA213223130000026007233566001",
+		Balance: 100.5,
+		Active:  true,
+		Country: "UA",
+		Token:   "token",
+	}
+}
+
+// newProcessors returns a processor that uses generated code and a processor with the same configuration
+// that encodes values with reflection only. Generated code is disabled if MaxDepth is set.
+func newProcessors(t *testing.T, cfg censor.Config) (generated, reflective *censor.Processor) {
+	t.Helper()
+
+	generated, err := censor.NewWithOpts(censor.WithConfig(&cfg))
+	require.NoError(t, err)
+
+	cfg.Encoder.MaxDepth = math.MaxInt
+	reflective, err = censor.NewWithOpts(censor.WithConfig(&cfg))
+	require.NoError(t, err)
+
+	return generated, reflective
+}
+
+func TestAppendCensoredJSON(t *testing.T) {
+	tests := map[string]struct {
+		cfg      func(c *censor.Config)
+		register func(p *censor.Processor)
+		// skipDirect contains types with rules that apply only when values are encoded by the processor.
+		skipDirect string
+	}{
+		"default": {},
+		"json_tag_names": {
+			cfg: func(c *censor.Config) { c.Encoder.UseJSONTagName = true },
+		},
+		"hash_key": {
+			cfg: func(c *censor.Config) { c.Encoder.HashKey = "key" },
+		},
+		"exclude_patterns": {
+			cfg: func(c *censor.Config) {
+				c.Encoder.ExcludePatterns = []string{`\d{3}`, `bio`}
+				c.Encoder.ExcludePatternsAction = censor.ActionHash
+				c.Encoder.HashKey = "key"
+			},
+		},
+		"default_field_action_omit": {
+			cfg: func(c *censor.Config) { c.Encoder.DefaultFieldAction = censor.ActionOmit },
+		},
+		"string_limit": {
+			cfg: func(c *censor.Config) {
+				c.Encoder.MaxStringLength = 3
+				c.Encoder.MaxSliceLength = 1
+				c.Encoder.MaxMapEntries = 1
+			},
+		},
+		"field_rules": {
+			cfg: func(c *censor.Config) {
+				c.Encoder.FieldRules = map[string]string{
+					"github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.User.Password": "display",
+					"github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.User.Email":    "omit",
+					"github.com/vpakhuchyi/censor/cmd/censorgen/internal/fixture.Contact.Phone": "display",
+					"*.City":     "mask=first1",
+					"*.Untagged": "display",
+					"*.Token":    "mask",
+				}
+			},
+		},
+		"deny_by_default": {
+			cfg: func(c *censor.Config) {
+				c.Encoder.DenyByDefault = true
+				c.Encoder.DisplayMapKeys = []string{"^role$"}
+			},
+		},
+		"registered_predeclared_type": {
+			register: func(p *censor.Processor) {
+				censor.RegisterFunc(p, func(s string) any { return strings.ToUpper(s) })
+				censor.RegisterFunc(p, func(int) any { return "int" })
+			},
+		},
+		"registered_struct": {
+			register: func(p *censor.Processor) {
+				censor.RegisterFunc(p, func(a Address) any { return a.City })
+			},
+			skipDirect: "address",
+		},
+		"text_format": {
+			cfg: func(c *censor.Config) { c.General.OutputFormat = censor.OutputFormatText },
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			cfg := censor.DefaultConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			generated, reflective := newProcessors(t, cfg)
+			if tt.register != nil {
+				tt.register(generated)
+				tt.register(reflective)
+			}
+
+			// The output of direct calls is always in the JSON format.
+			jsonCfg := cfg
+			jsonCfg.General.OutputFormat = censor.OutputFormatJSON
+			_, reflectiveJSON := newProcessors(t, jsonCfg)
+			if tt.register != nil {
+				tt.register(reflectiveJSON)
+			}
+
+			user := newUser()
+			values := map[string]any{
+				"user":         newUser(),
+				"account":      newAccount(),
+				"user_ptr":     &user,
+				"empty_user":   User{},
+				"tree":         Tree{Name: "root", Children: []*Tree{{Name: "child"}}},
+				"profile_ptr":  &Profile{Bio: "bio", Next: &Profile{}},
+				"address_list": []Address{{City: "Kyiv"}, {City: "Lviv"}},
+			}
+
+			for name, v := range values {
+				// WHEN.
+				got := generated.Any(v)
+
+				// THEN.
+				require.Equal(t, string(reflective.Any(v)), string(got), name)
+			}
+
+			appenders := map[string]censor.JSONAppender{
+				"user":    newUser(),
+				"account": newAccount(),
+				"tree":    Tree{Name: "root", Children: []*Tree{{Name: "child"}}},
+				"address": Address{City: "Kyiv", Street: "Khreshchatyk"},
+			}
+
+			for name, v := range appenders {
+				if name == tt.skipDirect {
+					continue
+				}
+
+				// WHEN.
+				got := v.AppendCensoredJSON([]byte("prefix:"), generated)
+
+				// THEN.
+				require.Equal(t, "prefix:"+string(reflectiveJSON.Any(v)), string(got), name)
+			}
+		})
+	}
+}
+
+func TestAppendCensoredJSON_Cycle(t *testing.T) {
+	// GIVEN.
+	generated, reflective := newProcessors(t, censor.DefaultConfig())
+	p := &Profile{Bio: "bio"}
+	p.Next = p
+
+	// WHEN.
+	got := generated.Any(p)
+
+	// THEN.
+	require.Equal(t, string(reflective.Any(p)), string(got))
+	require.Contains(t, string(got), "[CYCLE]")
+}
+
+func TestAppendCensoredJSON_Allocations(t *testing.T) {
+	// GIVEN.
+	generated, _ := newProcessors(t, censor.DefaultConfig())
+	v := Address{City: "Kyiv", Street: "Khreshchatyk"}
+	dst := make([]byte, 0, 256)
+
+	// WHEN.
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = v.AppendCensoredJSON(dst[:0], generated)
+	})
+
+	// THEN.
+	require.Zero(t, allocs)
+	require.Equal(t, `{"City": "Kyiv","Street": "[CENSORED]"}`, string(dst))
+}
+
+func BenchmarkAppendCensoredJSON(b *testing.B) {
+	cfg := censor.DefaultConfig()
+	generated, err := censor.NewWithOpts(censor.WithConfig(&cfg))
+	require.NoError(b, err)
+
+	cfg.Encoder.MaxDepth = math.MaxInt
+	reflective, err := censor.NewWithOpts(censor.WithConfig(&cfg))
+	require.NoError(b, err)
+
+	values := map[string]any{
+		"account": newAccount(),
+		"user":    newUser(),
+	}
+
+	for name, v := range values {
+		b.Run(name+"/generated", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				generated.Any(v)
+			}
+		})
+
+		b.Run(name+"/reflective", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				reflective.Any(v)
+			}
+		})
+	}
+
+	b.Run("account/direct", func(b *testing.B) {
+		v := newAccount()
+		dst := make([]byte, 0, 512)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dst = v.AppendCensoredJSON(dst[:0], generated)
+		}
+	})
+}
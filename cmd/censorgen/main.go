@@ -0,0 +1,58 @@
+// Censorgen generates AppendCensoredJSON methods for struct types, so censor processors that use
+// the JSON format encode them without reflection. The output of generated code is the same as the output
+// of a processor that inspects the types with reflection.
+//
+// Usage:
+//
+//	censorgen -type User,Address [-output censor_gen.go] [dir]
+//
+// It's intended to be used with go:generate:
+//
+//	//go:generate go run github.com/vpakhuchyi/censor/cmd/censorgen -type User,Address
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultOutput = "censor_gen.go"
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", defaultOutput, "output file name; relative paths are resolved against the package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: censorgen -type T1,T2 [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	out := *output
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	src, err := generate(dir, strings.Split(*typeNames, ","), filepath.Base(out))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "censorgen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err = os.WriteFile(out, src, 0o644); err != nil { //nolint:gosec // Generated source files are not secret.
+		fmt.Fprintf(os.Stderr, "censorgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,12 @@
+package invalid
+
+// Status is not a struct.
+type Status string
+
+// Page is a generic struct.
+type Page[T any] struct {
+	Items []T `censor:"display"`
+}
+
+// Alias is an alias of a struct type.
+type Alias = struct{ Name string }
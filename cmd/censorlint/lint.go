@@ -0,0 +1,480 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vpakhuchyi/censor/internal/encoder"
+)
+
+const censorTag = "censor"
+
+// sensitiveWords contains words that make a field name look sensitive.
+var sensitiveWords = map[string]bool{
+	"password": true,
+	"passwd":   true,
+	"token":    true,
+	"secret":   true,
+	"ssn":      true,
+	"card":     true,
+	"cvv":      true,
+}
+
+// printFuncs contains functions that format their arguments with reflection, keyed by package path.
+// Methods of log.Logger with the same names are checked as well.
+var printFuncs = map[string]map[string]bool{
+	"fmt": {
+		"Print": true, "Printf": true, "Println": true,
+		"Sprint": true, "Sprintf": true, "Sprintln": true,
+		"Fprint": true, "Fprintf": true, "Fprintln": true,
+		"Append": true, "Appendf": true, "Appendln": true,
+		"Errorf": true,
+	},
+	"log": {
+		"Print": true, "Printf": true, "Println": true,
+		"Fatal": true, "Fatalf": true, "Fatalln": true,
+		"Panic": true, "Panicf": true, "Panicln": true,
+	},
+}
+
+// Issue describes a problem found in the source code.
+type Issue struct {
+	Pos     token.Position
+	Message string
+}
+
+// String returns the issue in the "file:line:column: message" format.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Pos, i.Message)
+}
+
+// linter checks packages. Imported packages are loaded from source once and shared between checks.
+type linter struct {
+	fset     *token.FileSet
+	importer types.Importer
+	tests    bool
+}
+
+func newLinter(tests bool) *linter {
+	fset := token.NewFileSet()
+
+	return &linter{
+		fset:     fset,
+		importer: importer.ForCompiler(fset, "source", nil),
+		tests:    tests,
+	}
+}
+
+// expandPatterns returns directories that match the given patterns. A pattern with the "/..." suffix
+// matches the directory and all the directories below it that contain Go files, except testdata,
+// vendor and hidden directories.
+func expandPatterns(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		root, ok := strings.CutSuffix(pattern, "/...")
+		if !ok {
+			dirs = append(dirs, pattern)
+
+			continue
+		}
+
+		if root == "" {
+			root = "/"
+		}
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() {
+				return nil
+			}
+
+			name := d.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+
+			if hasGoFiles(path) {
+				dirs = append(dirs, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dirs, nil
+}
+
+// hasGoFiles reports whether the given directory contains Go files.
+func hasGoFiles(dir string) bool {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+
+	return err == nil && len(matches) != 0
+}
+
+// lintDir checks the package in the given directory. If test files are checked, an external test package
+// is checked separately. Type errors are ignored: the checks rely only on the types that could be resolved.
+func (l *linter) lintDir(dir string) ([]Issue, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string][]*ast.File)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || (!l.tests && strings.HasSuffix(name, "_test.go")) {
+			continue
+		}
+
+		f, err := parser.ParseFile(l.fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg := f.Name.Name
+		if _, ok := packages[pkg]; !ok {
+			names = append(names, pkg)
+		}
+		packages[pkg] = append(packages[pkg], f)
+	}
+
+	var issues []Issue
+	for _, name := range names {
+		issues = append(issues, l.lintPackage(dir, packages[name])...)
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i].Pos, issues[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return issues, nil
+}
+
+// lintPackage type-checks the given files of a single package and runs all the checks.
+func (l *linter) lintPackage(dir string, files []*ast.File) []Issue {
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer: l.importer,
+		Error:    func(error) {},
+	}
+	// Type errors are ignored, see lintDir.
+	_, _ = conf.Check(dir, l.fset, files, info)
+
+	c := checker{fset: l.fset, info: info, tagged: make(map[types.Type]bool)}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.StructType:
+				c.checkStruct(n)
+			case *ast.CallExpr:
+				c.checkCall(n)
+			}
+
+			return true
+		})
+	}
+
+	return c.issues
+}
+
+// checker runs the checks for a single package.
+type checker struct {
+	fset   *token.FileSet
+	info   *types.Info
+	issues []Issue
+	// tagged caches the results of hasCensorTags.
+	tagged map[types.Type]bool
+}
+
+func (c *checker) report(pos token.Pos, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Pos: c.fset.Position(pos), Message: fmt.Sprintf(format, args...)})
+}
+
+// checkStruct reports unknown censor tag values and displayed fields with sensitive names.
+func (c *checker) checkStruct(s *ast.StructType) {
+	for _, field := range s.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+
+		raw, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+
+		tag := reflect.StructTag(raw)
+		value, ok := tag.Lookup(censorTag)
+		if !ok {
+			continue
+		}
+
+		if !encoder.ValidTag(value) {
+			c.report(field.Tag.Pos(), "unknown censor tag value %q, the field is masked", value)
+
+			continue
+		}
+
+		if value != "display" {
+			continue
+		}
+
+		// Values of types that implement censor.Censorer are masked by their own method.
+		if tv, ok := c.info.Types[field.Type]; ok && implementsCensorer(tv.Type) {
+			continue
+		}
+
+		jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+		for _, name := range fieldNames(field) {
+			if looksSensitive(name) || looksSensitive(jsonName) {
+				c.report(field.Pos(), "field %s looks sensitive, but it's displayed with censor:\"display\"", name)
+			}
+		}
+	}
+}
+
+// fieldNames returns names of the given field. For embedded fields, the type name is returned.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		typ := field.Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+
+		switch t := typ.(type) {
+		case *ast.Ident:
+			return []string{t.Name}
+		case *ast.SelectorExpr:
+			return []string{t.Sel.Name}
+		default:
+			return nil
+		}
+	}
+
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+
+	return names
+}
+
+// looksSensitive reports whether any word of the given identifier is a sensitive word.
+// Words are separated by underscores, hyphens and case changes, e.g. "userSSN" and "api_token".
+func looksSensitive(name string) bool {
+	for _, word := range splitWords(name) {
+		word = strings.ToLower(word)
+		if sensitiveWords[word] || (len(word) > 3 && sensitiveWords[strings.TrimSuffix(word, "s")]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitWords splits an identifier into words. Sequences of upper case letters are treated as acronyms,
+// e.g. "SSNValue" is split into "SSN" and "Value".
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && !isWordBoundary(runes, i) {
+			continue
+		}
+
+		if word := strings.Trim(string(runes[start:i]), "_-"); word != "" {
+			words = append(words, word)
+		}
+		start = i
+	}
+
+	return words
+}
+
+// isWordBoundary reports whether a new word starts at the i-th rune.
+func isWordBoundary(runes []rune, i int) bool {
+	prev, cur := runes[i-1], runes[i]
+	switch {
+	case cur == '_' || cur == '-':
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(cur):
+		// The last upper case letter of an acronym starts a new word if it's followed by a lower case letter.
+		return i+1 < len(runes) && unicode.IsLower(runes[i+1])
+	default:
+		return false
+	}
+}
+
+// checkCall reports values with censor tags passed to print functions directly.
+func (c *checker) checkCall(call *ast.CallExpr) {
+	name, ok := c.printFunc(call)
+	if !ok || call.Ellipsis.IsValid() {
+		return
+	}
+
+	for _, arg := range call.Args {
+		tv, ok := c.info.Types[arg]
+		if !ok || tv.Type == nil || hasFormatMethod(tv.Type) || !c.hasCensorTags(tv.Type) {
+			continue
+		}
+
+		c.report(arg.Pos(), "%s is called with a value of type %s that has censor tags, format it with censor.Any first",
+			name, types.TypeString(tv.Type, packageName))
+	}
+}
+
+// printFunc returns the name of the called function if it's one of printFuncs.
+func (c *checker) printFunc(call *ast.CallExpr) (string, bool) {
+	var ident *ast.Ident
+	switch fn := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fn
+	case *ast.SelectorExpr:
+		ident = fn.Sel
+	default:
+		return "", false
+	}
+
+	fn, ok := c.info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || !printFuncs[fn.Pkg().Path()][fn.Name()] {
+		return "", false
+	}
+
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return "", false
+	}
+
+	if recv := sig.Recv(); recv != nil {
+		// Only methods of log.Logger are checked.
+		named, ok := types.Unalias(derefType(recv.Type())).(*types.Named)
+		if !ok || named.Obj().Name() != "Logger" {
+			return "", false
+		}
+
+		return fn.Pkg().Name() + ".Logger." + fn.Name(), true
+	}
+
+	return fn.Pkg().Name() + "." + fn.Name(), true
+}
+
+// hasCensorTags reports whether values of the given type contain structs with censor tags.
+// Pointers, slices, arrays, maps and nested structs are inspected.
+func (c *checker) hasCensorTags(t types.Type) bool {
+	if tagged, ok := c.tagged[t]; ok {
+		return tagged
+	}
+
+	tagged := c.inspectType(t, make(map[types.Type]bool))
+	c.tagged[t] = tagged
+
+	return tagged
+}
+
+// inspectType is the same as hasCensorTags, but types in the visiting set are considered untagged,
+// so recursive types are inspected once. Only positive results of nested types are cached,
+// as a negative one may depend on a type that is still being inspected.
+func (c *checker) inspectType(t types.Type, visiting map[types.Type]bool) bool {
+	if tagged, ok := c.tagged[t]; ok {
+		return tagged
+	}
+
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	var tagged bool
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		tagged = c.inspectType(u.Elem(), visiting)
+	case *types.Slice:
+		tagged = c.inspectType(u.Elem(), visiting)
+	case *types.Array:
+		tagged = c.inspectType(u.Elem(), visiting)
+	case *types.Map:
+		tagged = c.inspectType(u.Elem(), visiting)
+	case *types.Struct:
+		for i := 0; i < u.NumFields() && !tagged; i++ {
+			_, tagged = reflect.StructTag(u.Tag(i)).Lookup(censorTag)
+			tagged = tagged || c.inspectType(u.Field(i).Type(), visiting)
+		}
+	}
+
+	if tagged {
+		c.tagged[t] = true
+	}
+
+	return tagged
+}
+
+// hasFormatMethod reports whether values of the given type are formatted with their own methods,
+// i.e. String, Error or Format, so their fields are not printed as is.
+func hasFormatMethod(t types.Type) bool {
+	return hasMethod(t, "String") || hasMethod(t, "Error") || hasMethod(t, "Format")
+}
+
+// censorerType is the censor.Censorer interface.
+var censorerType = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "CensorValue", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.NewInterfaceType(nil, nil))), false)),
+}, nil).Complete()
+
+// implementsCensorer reports whether the given type or a pointer to it implements censor.Censorer.
+func implementsCensorer(t types.Type) bool {
+	return types.Implements(t, censorerType) || types.Implements(types.NewPointer(t), censorerType)
+}
+
+// hasMethod reports whether the given type or a pointer to it has an exported method with the given name.
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+
+	return ok
+}
+
+// packageName qualifies type names with package names instead of import paths.
+func packageName(p *types.Package) string {
+	return p.Name()
+}
+
+// derefType returns the element type of a pointer type or the type itself.
+func derefType(t types.Type) types.Type {
+	if p, ok := t.(*types.Pointer); ok {
+		return p.Elem()
+	}
+
+	return t
+}
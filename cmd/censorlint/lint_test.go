@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinter_lintDir(t *testing.T) {
+	// GIVEN.
+	l := newLinter(false)
+	dir := filepath.Join("testdata", "src", "sample")
+
+	// WHEN.
+	issues, err := l.lintDir(dir)
+
+	// THEN.
+	require.NoError(t, err)
+
+	got := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+
+	file := filepath.Join(dir, "sample.go")
+	require.Equal(t, []string{
+		file + `:13:2: field Password looks sensitive, but it's displayed with censor:"display"`,
+		file + `:14:2: field AccessToken looks sensitive, but it's displayed with censor:"display"`,
+		file + `:15:2: field Key looks sensitive, but it's displayed with censor:"display"`,
+		file + `:16:2: field UserSSN looks sensitive, but it's displayed with censor:"display"`,
+		file + `:19:21: unknown censor tag value "show", the field is masked`,
+		file + `:20:21: unknown censor tag value "mask=last0", the field is masked`,
+		file + `:48:14: fmt.Println is called with a value of type sample.User that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:49:24: fmt.Printf is called with a value of type *sample.User that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:50:17: fmt.Sprint is called with a value of type sample.Wrapper that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:51:26: fmt.Fprintln is called with a value of type []sample.User that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:52:26: log.Printf is called with a value of type sample.User that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:53:36: log.Logger.Println is called with a value of type map[string]sample.User that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:54:29: fmt.Errorf is called with a value of type sample.User that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:65:2: field Token looks sensitive, but it's displayed with censor:"display"`,
+		file + `:78:14: fmt.Println is called with a value of type sample.Order that has censor tags, ` +
+			`format it with censor.Any first`,
+		file + `:79:14: fmt.Println is called with a value of type sample.Customer that has censor tags, ` +
+			`format it with censor.Any first`,
+	}, got)
+}
+
+func Test_looksSensitive(t *testing.T) {
+	tests := map[string]bool{
+		"Password":      true,
+		"password":      true,
+		"AccessToken":   true,
+		"api_token":     true,
+		"Tokens":        true,
+		"SSN":           true,
+		"SSNValue":      true,
+		"userSSN":       true,
+		"CardNumber":    true,
+		"client-secret": true,
+		"Discard":       false,
+		"Passage":       false,
+		"Tokenizer":     false,
+		"ID":            false,
+		"":              false,
+	}
+
+	for name, exp := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, exp, looksSensitive(name))
+		})
+	}
+}
+
+func Test_expandPatterns(t *testing.T) {
+	// WHEN.
+	dirs, err := expandPatterns([]string{"./...", "testdata/src/sample"})
+
+	// THEN.
+	require.NoError(t, err)
+	require.Equal(t, []string{".", filepath.Join("testdata", "src", "sample")}, dirs)
+}
@@ -0,0 +1,59 @@
+// Censorlint reports code that may leak sensitive data despite the use of censor:
+//
+//   - struct fields with sensitive names (password, token, secret, etc.) tagged with `censor:"display"`;
+//   - unknown `censor` tag values, which are silently treated as "mask";
+//   - values of structs with `censor` tags passed directly to fmt.Print*, log.Print*, etc.
+//     instead of being formatted with censor.Any first.
+//
+// Usage:
+//
+//	censorlint [-tests] [packages]
+//
+// Packages are directories; the "/..." suffix matches all the directories below. The default is "./...".
+// The exit code is 1 if any issue is found and 2 if packages can't be loaded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	tests := flag.Bool("tests", false, "check test files as well")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: censorlint [-tests] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	dirs, err := expandPatterns(patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "censorlint: %v\n", err)
+		os.Exit(2)
+	}
+
+	l := newLinter(*tests)
+	var found bool
+	for _, dir := range dirs {
+		issues, err := l.lintDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "censorlint: %v\n", err)
+			os.Exit(2)
+		}
+
+		for _, issue := range issues {
+			fmt.Println(issue)
+			found = true
+		}
+	}
+
+	if found {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+package sample
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/vpakhuchyi/censor"
+)
+
+type User struct {
+	ID          string `censor:"display"`
+	Password    string `censor:"display"`
+	AccessToken string `censor:"display" json:"access_token"`
+	Key         string `censor:"display" json:"api_secret"`
+	UserSSN     string `censor:"display"`
+	CardNumber  string `censor:"mask=last4"`
+	Discard     bool   `censor:"display"`
+	Email       string `censor:"show"`
+	Phone       string `censor:"mask=last0"`
+	Name        string
+}
+
+type Credentials struct {
+	Token Token `censor:"display"`
+}
+
+// Token implements censor.Censorer.
+type Token string
+
+func (Token) CensorValue() any { return "[TOKEN]" }
+
+type Wrapper struct {
+	Users []*User
+}
+
+type Plain struct {
+	Name string
+}
+
+type Stringer struct {
+	Secret string `censor:"mask"`
+}
+
+func (Stringer) String() string { return "stringer" }
+
+func print(u User, w Wrapper, p Plain, s Stringer, args []any) {
+	fmt.Println(u)
+	fmt.Printf("%v %v\n", &u, p)
+	_ = fmt.Sprint(w)
+	fmt.Fprintln(os.Stdout, []User{u})
+	log.Printf("user: %+v", u)
+	log.New(os.Stderr, "", 0).Println(map[string]User{})
+	_ = fmt.Errorf("user: %v", u)
+	fmt.Println(string(censor.Any(u)), p, s)
+	fmt.Println(args...)
+}
+
+// FakeToken has a CensorValue method that doesn't implement censor.Censorer.
+type FakeToken string
+
+func (FakeToken) CensorValue() string { return "[TOKEN]" }
+
+type FakeCredentials struct {
+	Token FakeToken `censor:"display"`
+}
+
+type Order struct {
+	Customer *Customer
+	ID       string `censor:"display"`
+}
+
+type Customer struct {
+	Order *Order
+}
+
+func printRecursive(o Order, c Customer) {
+	fmt.Println(o)
+	fmt.Println(c)
+}
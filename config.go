@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -20,6 +21,37 @@ const (
 	OutputFormatJSON = "json"
 	// OutputFormatText is used to set the output format to text.
 	OutputFormatText = "text"
+
+	// ActionMask is used to replace a value with the mask value.
+	ActionMask = "mask"
+	// ActionHash is used to replace a value with a truncated keyed HMAC-SHA256 digest.
+	ActionHash = "hash"
+	// ActionOmit is used to drop a struct field from the output entirely.
+	ActionOmit = "omit"
+
+	// BytesFormatBase64 is used to write byte slices as base64 strings.
+	BytesFormatBase64 = "base64"
+	// BytesFormatHex is used to write byte slices as hexadecimal strings.
+	BytesFormatHex = "hex"
+	// BytesFormatString is used to write byte slices as strings that are checked against the exclude patterns.
+	BytesFormatString = "string"
+	// BytesFormatLengthOnly is used to write only the length of byte slices.
+	BytesFormatLengthOnly = "length-only"
+
+	// CacheModePlain is used to cache encoded strings by their original values.
+	CacheModePlain = "plain"
+	// CacheModeHashed is used to cache encoded strings by keyed digests of their original values,
+	// so the original values are not kept in memory.
+	CacheModeHashed = "hashed"
+	// CacheModeDisabled is used to disable caching of encoded strings.
+	CacheModeDisabled = "disabled"
+
+	// DefaultEntropyMinLength is the default minimum length of tokens checked by the entropy detector.
+	DefaultEntropyMinLength = 20
+	// DefaultEntropyMinEntropy is the default minimum Shannon entropy of masked tokens in bits per character.
+	DefaultEntropyMinEntropy = 3.5
+	// DefaultEntropyMinCharClasses is the default minimum number of character classes of masked tokens.
+	DefaultEntropyMinCharClasses = 3
 )
 
 // Config describes the available encoder.Encoder and formatter.Formatter configuration.
@@ -47,6 +79,188 @@ type EncoderConfig struct {
 	ExcludePatterns      []string `yaml:"exclude-patterns"`
 	MaskValue            string   `yaml:"mask-value"`
 	UseJSONTagName       bool     `yaml:"use-json-tag-name"`
+	// NamedExcludePatterns contains regexp patterns that are applied to strings along with ExcludePatterns,
+	// but may have their own replacements, e.g. a label like "[EMAIL]" or a template that keeps some
+	// of the pattern groups like "$1***@$2". Patterns without a replacement are replaced the same way
+	// as ExcludePatterns matches. Up to 50 patterns are allowed.
+	NamedExcludePatterns []NamedExcludePattern `yaml:"named-exclude-patterns,omitempty"`
+	// ExcludePatternsAction sets what happens to strings matched by ExcludePatterns:
+	// ActionMask (default) replaces them with MaskValue, ActionHash replaces them with a digest.
+	ExcludePatternsAction string `yaml:"exclude-patterns-action,omitempty"`
+	// Detectors contains names of the built-in detectors of sensitive values, which are applied to strings
+	// along with ExcludePatterns: "aws-access-key", "bearer-token", "credit-card", "email", "iban", "ipv4", "ipv6",
+	// "jwt" and "phone-e164". Unlike plain patterns, some detectors validate the found values, e.g. card numbers
+	// are checked with the Luhn algorithm. Detectors don't count towards the limit of ExcludePatterns.
+	Detectors []string `yaml:"detectors,omitempty"`
+	// Entropy configures the detection of high-entropy tokens in strings, e.g. API keys, random passwords
+	// or session IDs that don't have a recognizable format. Detected tokens are replaced the same way
+	// as ExcludePatterns matches.
+	Entropy EntropyConfig `yaml:"entropy,omitempty"`
+	// Secrets describes where to load literal values that are masked in all strings, see Processor.RegisterSecrets.
+	Secrets SecretsConfig `yaml:"secrets,omitempty"`
+	// HashKey is a secret key used to compute digests of values tagged with `censor:"hash"`
+	// and of ExcludePatterns matches when ExcludePatternsAction is ActionHash.
+	// Digests are stable across processes that share the same key.
+	HashKey string `yaml:"hash-key,omitempty"`
+	// DefaultFieldAction sets what happens to struct fields without a censor tag:
+	// ActionMask (default), ActionHash or ActionOmit. ActionHash requires HashKey.
+	DefaultFieldAction string `yaml:"default-field-action,omitempty"`
+	// MaskMapKeys contains regexp patterns of map keys, e.g. "(?i)pass" or "authorization".
+	// Values of matched string keys are masked entirely, including nested maps and slices.
+	// Up to 50 patterns are allowed.
+	MaskMapKeys []string `yaml:"mask-map-keys,omitempty"`
+	// DenyByDefault makes map values and loose scalars (e.g. a string passed to Any) masked,
+	// the same way as struct fields without a censor tag. Values reached through struct fields
+	// with the display action stay visible, and map values stay visible only if their keys are matched
+	// by DisplayMapKeys.
+	DenyByDefault bool `yaml:"deny-by-default,omitempty"`
+	// DisplayMapKeys contains regexp patterns of map keys whose values are displayed in the deny-by-default mode,
+	// e.g. "^id$" or "^status$". Patterns should be anchored, so a safe key doesn't expose similar keys.
+	// Up to 50 patterns are allowed.
+	DisplayMapKeys []string `yaml:"display-map-keys,omitempty"`
+	// BytesFormat sets how byte slices (e.g. request bodies) are written: BytesFormatBase64, BytesFormatHex,
+	// BytesFormatString or BytesFormatLengthOnly. By default, they are written as lists of numbers.
+	// Regardless of the format, json.RawMessage values that contain valid JSON are embedded the same way
+	// as documents processed by Processor.JSON.
+	BytesFormat string `yaml:"bytes-format,omitempty"`
+	// Cache configures the internal caches of encoded strings and matched map keys.
+	Cache CacheConfig `yaml:"cache,omitempty"`
+	// FieldRules overrides or supplements censor tags of struct fields. Keys are dotted paths to fields:
+	// a type name qualified with the package path or "*" for any type, and a field name,
+	// e.g. "github.com/acme/api.User.Email" or "*.Password". Values are censor tag values, e.g. "display",
+	// "mask", "mask=last4", "hash" or "omit". Rules for specific types take precedence over "*" rules.
+	FieldRules map[string]string `yaml:"field-rules,omitempty"`
+	// MaxDepth limits how deep nested structs, maps, slices and pointers are encoded.
+	// Values below the limit are replaced with "...". Zero means no limit.
+	MaxDepth int `yaml:"max-depth,omitempty"`
+	// MaxSliceLength limits the number of written slice and array elements. Zero means no limit.
+	MaxSliceLength int `yaml:"max-slice-length,omitempty"`
+	// MaxMapEntries limits the number of written map entries. Zero means no limit.
+	MaxMapEntries int `yaml:"max-map-entries,omitempty"`
+	// MaxStringLength limits the number of written characters of a string after masking. Map keys are never truncated.
+	// Zero means no limit.
+	MaxStringLength int `yaml:"max-string-length,omitempty"`
+	// MaxOutputBytes is a soft limit of the output size. Once it's reached, remaining struct fields,
+	// map entries and slice elements are skipped. Zero means no limit.
+	MaxOutputBytes int `yaml:"max-output-bytes,omitempty"`
+}
+
+// NamedExcludePattern describes a regexp pattern of strings that must be replaced.
+type NamedExcludePattern struct {
+	// Name identifies the pattern, e.g. "email". Names must be unique.
+	Name string `yaml:"name"`
+	// Pattern is a regexp pattern of the replaced strings.
+	Pattern string `yaml:"pattern"`
+	// Replace is a template of the replacement, e.g. "[EMAIL]" or "$1***@$2". It may refer to the pattern
+	// groups by numbers or names the same way as regexp.Regexp.Expand. If it's empty, matches are replaced
+	// the same way as ExcludePatterns matches.
+	Replace string `yaml:"replace,omitempty"`
+}
+
+// CacheConfig describes the internal caches of encoded strings and matched map keys. The caches speed up
+// matching of strings that are seen over and over again, but by default they keep up to Size original strings
+// in memory as keys, including sensitive ones. Such strings may be exposed by heap dumps.
+type CacheConfig struct {
+	// Mode sets how strings are cached: CacheModePlain (default) uses the original strings as keys,
+	// CacheModeHashed uses keyed HMAC-SHA256 digests of them as keys, and CacheModeDisabled turns the caches off.
+	// In the hashed mode, the key is random and is never stored in the configuration.
+	Mode string `yaml:"mode,omitempty"`
+	// Size is the maximum number of entries of each cache. The default value is 500.
+	Size int `yaml:"size,omitempty"`
+}
+
+// toEncoderCacheMode converts a validated cache mode name to encoder.CacheMode.
+func toEncoderCacheMode(mode string) encoder.CacheMode {
+	switch mode {
+	case CacheModeHashed:
+		return encoder.CacheHashed
+	case CacheModeDisabled:
+		return encoder.CacheDisabled
+	default:
+		return encoder.CachePlain
+	}
+}
+
+// SecretsConfig describes sources of literal values that are registered with Processor.RegisterSecrets
+// when a Processor is created. Only the names of the sources are stored in the configuration,
+// so it's safe to print it.
+type SecretsConfig struct {
+	// Env contains names of environment variables with secrets, e.g. "DB_PASSWORD".
+	Env []string `yaml:"env,omitempty"`
+	// Files contains paths to files with secrets, e.g. "/run/secrets/api_key". Each file contains one secret,
+	// trailing line breaks are ignored.
+	Files []string `yaml:"files,omitempty"`
+}
+
+// load returns the secrets from the configured sources.
+// It returns an error if any variable is not set or any file can't be read or is empty.
+func (c SecretsConfig) load() ([]string, error) {
+	secrets := make([]string, 0, len(c.Env)+len(c.Files))
+	for _, name := range c.Env {
+		v := os.Getenv(name)
+		if v == "" {
+			return nil, fmt.Errorf("environment variable %q is empty or not set", name)
+		}
+		secrets = append(secrets, v)
+	}
+
+	for _, path := range c.Files {
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		v := strings.TrimRight(string(b), "\r\n")
+		if v == "" {
+			return nil, fmt.Errorf("file %q is empty", path)
+		}
+		secrets = append(secrets, v)
+	}
+
+	return secrets, nil
+}
+
+// EntropyConfig describes the detector of high-entropy tokens. Tokens are sequences of letters, digits
+// and '+', '/', '_', '-' characters with optional '=' padding. A token is replaced if it's long enough,
+// contains enough character classes (lowercase letters, uppercase letters and digits) and its Shannon entropy
+// is high enough. Zero values are replaced with the defaults.
+type EntropyConfig struct {
+	// Enabled turns the detector on. The default value is false.
+	Enabled bool `yaml:"enabled"`
+	// MinLength is the minimum length of a token. The default value is DefaultEntropyMinLength.
+	MinLength int `yaml:"min-length,omitempty"`
+	// MinEntropy is the minimum Shannon entropy of a token in bits per character.
+	// The default value is DefaultEntropyMinEntropy. Random base64 tokens usually have 4 bits or more,
+	// while words and identifiers rarely exceed 3.5 bits.
+	MinEntropy float64 `yaml:"min-entropy,omitempty"`
+	// MinCharClasses is the minimum number of character classes a token must contain: from 1 to 3.
+	// The default value is DefaultEntropyMinCharClasses. Set it to 2 to detect hexadecimal tokens.
+	MinCharClasses int `yaml:"min-char-classes,omitempty"`
+}
+
+// toEncoderConfig returns the encoder configuration of the entropy detector with the defaults applied,
+// or nil if the detector is disabled.
+func (c EntropyConfig) toEncoderConfig() *encoder.EntropyConfig {
+	if !c.Enabled {
+		return nil
+	}
+
+	ec := &encoder.EntropyConfig{
+		MinLength:      DefaultEntropyMinLength,
+		MinEntropy:     DefaultEntropyMinEntropy,
+		MinCharClasses: DefaultEntropyMinCharClasses,
+	}
+	if c.MinLength != 0 {
+		ec.MinLength = c.MinLength
+	}
+	if c.MinEntropy != 0 {
+		ec.MinEntropy = c.MinEntropy
+	}
+	if c.MinCharClasses != 0 {
+		ec.MinCharClasses = c.MinCharClasses
+	}
+
+	return ec
 }
 
 func (c EncoderConfig) toEncoderConfig() encoder.Config {
@@ -57,6 +271,66 @@ func (c EncoderConfig) toEncoderConfig() encoder.Config {
 		ExcludePatterns:      c.ExcludePatterns,
 		MaskValue:            c.MaskValue,
 		UseJSONTagName:       c.UseJSONTagName,
+		HashKey:              c.HashKey,
+		HashExcludePatterns:  c.ExcludePatternsAction == ActionHash,
+		NamedPatterns:        toEncoderNamedPatterns(c.NamedExcludePatterns),
+		Detectors:            c.Detectors,
+		Entropy:              c.Entropy.toEncoderConfig(),
+		DefaultFieldAction:   toEncoderAction(c.DefaultFieldAction),
+		FieldRules:           c.FieldRules,
+		MaskMapKeys:          c.MaskMapKeys,
+		DenyByDefault:        c.DenyByDefault,
+		DisplayMapKeys:       c.DisplayMapKeys,
+		BytesFormat:          toEncoderBytesFormat(c.BytesFormat),
+		CacheMode:            toEncoderCacheMode(c.Cache.Mode),
+		CacheSize:            c.Cache.Size,
+		MaxDepth:             c.MaxDepth,
+		MaxSliceLength:       c.MaxSliceLength,
+		MaxMapEntries:        c.MaxMapEntries,
+		MaxStringLength:      c.MaxStringLength,
+		MaxOutputBytes:       c.MaxOutputBytes,
+	}
+}
+
+// toEncoderNamedPatterns converts named exclude patterns to encoder.NamedPattern.
+func toEncoderNamedPatterns(patterns []NamedExcludePattern) []encoder.NamedPattern {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	converted := make([]encoder.NamedPattern, 0, len(patterns))
+	for _, p := range patterns {
+		converted = append(converted, encoder.NamedPattern(p))
+	}
+
+	return converted
+}
+
+// toEncoderAction converts a validated action name to encoder.Action.
+func toEncoderAction(action string) encoder.Action {
+	switch action {
+	case ActionHash:
+		return encoder.ActionHash
+	case ActionOmit:
+		return encoder.ActionOmit
+	default:
+		return encoder.ActionMask
+	}
+}
+
+// toEncoderBytesFormat converts a validated bytes format name to encoder.BytesFormat.
+func toEncoderBytesFormat(format string) encoder.BytesFormat {
+	switch format {
+	case BytesFormatBase64:
+		return encoder.BytesBase64
+	case BytesFormatHex:
+		return encoder.BytesHex
+	case BytesFormatString:
+		return encoder.BytesString
+	case BytesFormatLengthOnly:
+		return encoder.BytesLength
+	default:
+		return encoder.BytesNumbers
 	}
 }
 
@@ -78,7 +352,10 @@ func DefaultConfig() Config {
 	}
 }
 
-const maxRegExPatterns = 50
+const (
+	maxRegExPatterns      = 50
+	maxEntropyCharClasses = 3
+)
 
 // Validate checks whether the configuration is valid.
 func (c Config) Validate() error {
@@ -92,13 +369,189 @@ func (c Config) Validate() error {
 		return fmt.Errorf("mask value cannot be empty")
 	}
 
-	if len(c.Encoder.ExcludePatterns) > maxRegExPatterns {
-		return fmt.Errorf("too many exclude patterns (max %d): %d", maxRegExPatterns, len(c.Encoder.ExcludePatterns))
+	if err := c.Encoder.validatePatterns(); err != nil {
+		return err
 	}
 
-	for _, pattern := range c.Encoder.ExcludePatterns {
+	if err := c.Encoder.validateActions(); err != nil {
+		return err
+	}
+
+	if err := c.Encoder.validateFieldRules(); err != nil {
+		return err
+	}
+
+	if err := c.Encoder.validateDetectors(); err != nil {
+		return err
+	}
+
+	if err := c.Encoder.validateSecrets(); err != nil {
+		return err
+	}
+
+	return c.Encoder.validateLimits()
+}
+
+func (c EncoderConfig) validatePatterns() error {
+	if err := validatePatterns("exclude", c.ExcludePatterns); err != nil {
+		return err
+	}
+
+	if err := c.validateNamedPatterns(); err != nil {
+		return err
+	}
+
+	if err := validatePatterns("mask map key", c.MaskMapKeys); err != nil {
+		return err
+	}
+
+	return validatePatterns("display map key", c.DisplayMapKeys)
+}
+
+func validatePatterns(kind string, patterns []string) error {
+	if len(patterns) > maxRegExPatterns {
+		return fmt.Errorf("too many %s patterns (max %d): %d", kind, maxRegExPatterns, len(patterns))
+	}
+
+	for _, pattern := range patterns {
 		if _, err := regexp.Compile(pattern); err != nil {
-			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+			return fmt.Errorf("invalid %s pattern %q: %w", kind, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (c EncoderConfig) validateNamedPatterns() error {
+	if len(c.NamedExcludePatterns) > maxRegExPatterns {
+		return fmt.Errorf("too many named exclude patterns (max %d): %d", maxRegExPatterns, len(c.NamedExcludePatterns))
+	}
+
+	names := make(map[string]struct{}, len(c.NamedExcludePatterns))
+	for i, p := range c.NamedExcludePatterns {
+		if p.Name == "" {
+			return fmt.Errorf("named exclude pattern #%d: name cannot be empty", i+1)
+		}
+
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate named exclude pattern %q", p.Name)
+		}
+		names[p.Name] = struct{}{}
+
+		if p.Pattern == "" {
+			return fmt.Errorf("named exclude pattern %q: pattern cannot be empty", p.Name)
+		}
+
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("invalid named exclude pattern %q: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c EncoderConfig) validateDetectors() error {
+	for _, name := range c.Detectors {
+		if !encoder.ValidDetector(name) {
+			return fmt.Errorf("unknown detector %q, must be one of: %s", name, strings.Join(encoder.DetectorNames(), ", "))
+		}
+	}
+
+	switch {
+	case c.Entropy.MinLength < 0:
+		return fmt.Errorf("entropy min length cannot be negative: %d", c.Entropy.MinLength)
+	case c.Entropy.MinEntropy < 0:
+		return fmt.Errorf("entropy min entropy cannot be negative: %g", c.Entropy.MinEntropy)
+	case c.Entropy.MinCharClasses < 0 || c.Entropy.MinCharClasses > maxEntropyCharClasses:
+		return fmt.Errorf("invalid entropy min char classes: %d, must be from 1 to %d",
+			c.Entropy.MinCharClasses, maxEntropyCharClasses)
+	}
+
+	return nil
+}
+
+func (c EncoderConfig) validateSecrets() error {
+	if slices.Contains(c.Secrets.Env, "") {
+		return fmt.Errorf("secret environment variable name cannot be empty")
+	}
+
+	if slices.Contains(c.Secrets.Files, "") {
+		return fmt.Errorf("secret file path cannot be empty")
+	}
+
+	return nil
+}
+
+func (c EncoderConfig) validateActions() error {
+	switch c.ExcludePatternsAction {
+	case "", ActionMask:
+	case ActionHash:
+		if c.HashKey == "" {
+			return fmt.Errorf("hash key cannot be empty when exclude patterns action is %q", ActionHash)
+		}
+	default:
+		return fmt.Errorf("invalid exclude patterns action: %q, must be %q or %q",
+			c.ExcludePatternsAction, ActionMask, ActionHash)
+	}
+
+	switch c.DefaultFieldAction {
+	case "", ActionMask, ActionOmit:
+	case ActionHash:
+		if c.HashKey == "" {
+			return fmt.Errorf("hash key cannot be empty when default field action is %q", ActionHash)
+		}
+	default:
+		return fmt.Errorf("invalid default field action: %q, must be %q, %q or %q",
+			c.DefaultFieldAction, ActionMask, ActionHash, ActionOmit)
+	}
+
+	switch c.Cache.Mode {
+	case "", CacheModePlain, CacheModeHashed, CacheModeDisabled:
+	default:
+		return fmt.Errorf("invalid cache mode: %q, must be %q, %q or %q",
+			c.Cache.Mode, CacheModePlain, CacheModeHashed, CacheModeDisabled)
+	}
+
+	switch c.BytesFormat {
+	case "", BytesFormatBase64, BytesFormatHex, BytesFormatString, BytesFormatLengthOnly:
+	default:
+		return fmt.Errorf("invalid bytes format: %q, must be %q, %q, %q or %q",
+			c.BytesFormat, BytesFormatBase64, BytesFormatHex, BytesFormatString, BytesFormatLengthOnly)
+	}
+
+	return nil
+}
+
+func (c EncoderConfig) validateFieldRules() error {
+	for path, value := range c.FieldRules {
+		if !encoder.ValidFieldRulePath(path) {
+			return fmt.Errorf("invalid field rule path %q, must be like %q or %q", path, "github.com/acme/api.User.Email", "*.Email")
+		}
+
+		if !encoder.ValidTag(value) {
+			return fmt.Errorf("invalid field rule %q: unknown action %q", path, value)
+		}
+	}
+
+	return nil
+}
+
+func (c EncoderConfig) validateLimits() error {
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{name: "max depth", value: c.MaxDepth},
+		{name: "max slice length", value: c.MaxSliceLength},
+		{name: "max map entries", value: c.MaxMapEntries},
+		{name: "max string length", value: c.MaxStringLength},
+		{name: "max output bytes", value: c.MaxOutputBytes},
+		{name: "cache size", value: c.Cache.Size},
+	}
+
+	for _, l := range limits {
+		if l.value < 0 {
+			return fmt.Errorf("%s cannot be negative: %d", l.name, l.value)
 		}
 	}
 
@@ -165,6 +618,11 @@ func (c Config) ToString() string {
 	b.WriteString(strings.Repeat(" ", (lineLength-len(text))/2) + text + "\n")
 	writeLine()
 
+	// The hash key is a secret, so it must never be printed.
+	if c.Encoder.HashKey != "" {
+		c.Encoder.HashKey = DefaultMaskValue
+	}
+
 	// config.Config and its nested fields contain only supported YAML types, so it must be marshalled successfully.
 	// However, if the configuration is changed, it may contain unsupported types. To handle this case,
 	// we have tests that check whether the configuration can be marshalled.
@@ -115,6 +115,44 @@ func TestConfig_FromFile(t *testing.T) {
 			want:    Config{},
 			wantErr: true,
 		},
+		"field_rules": {
+			args: args{
+				path: "./testdata/field_rules.yml",
+			},
+			want: Config{
+				General: General{
+					OutputFormat: OutputFormatJSON,
+				},
+				Encoder: EncoderConfig{
+					MaskValue: "[CENSORED]",
+					FieldRules: map[string]string{
+						"github.com/acme/api.User.Email": "display",
+						"*.Password":                     "omit",
+						"*.Card":                         "mask=last4",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		"named_exclude_patterns": {
+			args: args{
+				path: "./testdata/named_exclude_patterns.yml",
+			},
+			want: Config{
+				General: General{
+					OutputFormat: OutputFormatJSON,
+				},
+				Encoder: EncoderConfig{
+					MaskValue: "[CENSORED]",
+					NamedExcludePatterns: []NamedExcludePattern{
+						{Name: "email", Pattern: `(\w)[\w.]*@([\w.]+)`, Replace: "$1***@$2"},
+						{Name: "ssn", Pattern: `\d{3}-\d{2}-\d{4}`, Replace: "[SSN]"},
+						{Name: "token", Pattern: `tok_\w+`},
+					},
+				},
+			},
+			wantErr: false,
+		},
 		"valid_yaml_extension": {
 			args: args{
 				path: "./testdata/cfg.yaml",
@@ -211,6 +249,315 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr:         "invalid exclude pattern \"(\"",
 			wantErrContains: true,
 		},
+		"mask_map_keys": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.MaskMapKeys = []string{"(?i)pass", "token", "^authorization$"}
+				return cfg
+			}(),
+		},
+		"too_many_mask_map_keys": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.MaskMapKeys = make([]string, 51)
+				return cfg
+			}(),
+			wantErr: "too many mask map key patterns (max 50): 51",
+		},
+		"invalid_mask_map_key": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.MaskMapKeys = []string{"[a-"}
+				return cfg
+			}(),
+			wantErr:         "invalid mask map key pattern \"[a-\"",
+			wantErrContains: true,
+		},
+		"deny_by_default": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.DenyByDefault = true
+				cfg.Encoder.DisplayMapKeys = []string{"^id$", "^status$"}
+				return cfg
+			}(),
+		},
+		"invalid_display_map_key": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.DisplayMapKeys = []string{"(id"}
+				return cfg
+			}(),
+			wantErr:         "invalid display map key pattern \"(id\"",
+			wantErrContains: true,
+		},
+		"bytes_format": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.BytesFormat = BytesFormatLengthOnly
+				return cfg
+			}(),
+		},
+		"invalid_bytes_format": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.BytesFormat = "base32"
+				return cfg
+			}(),
+			wantErr: "invalid bytes format: \"base32\", must be \"base64\", \"hex\", \"string\" or \"length-only\"",
+		},
+		"detectors": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Detectors = []string{"credit-card", "iban", "email"}
+				return cfg
+			}(),
+		},
+		"unknown_detector": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Detectors = []string{"email", "passport"}
+				return cfg
+			}(),
+			wantErr: "unknown detector \"passport\", must be one of: aws-access-key, bearer-token, credit-card, " +
+				"email, iban, ipv4, ipv6, jwt, phone-e164",
+		},
+		"named_exclude_patterns": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.NamedExcludePatterns = []NamedExcludePattern{
+					{Name: "email", Pattern: `(\w)[\w.]*@([\w.]+)`, Replace: "$1***@$2"},
+					{Name: "token", Pattern: `tok_\w+`},
+				}
+				return cfg
+			}(),
+		},
+		"too_many_named_exclude_patterns": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.NamedExcludePatterns = make([]NamedExcludePattern, 51)
+				return cfg
+			}(),
+			wantErr: "too many named exclude patterns (max 50): 51",
+		},
+		"named_exclude_pattern_without_name": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.NamedExcludePatterns = []NamedExcludePattern{{Name: "a", Pattern: `a`}, {Pattern: `\d`}}
+				return cfg
+			}(),
+			wantErr: "named exclude pattern #2: name cannot be empty",
+		},
+		"duplicate_named_exclude_pattern": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.NamedExcludePatterns = []NamedExcludePattern{{Name: "a", Pattern: `a`}, {Name: "a", Pattern: `b`}}
+				return cfg
+			}(),
+			wantErr: "duplicate named exclude pattern \"a\"",
+		},
+		"named_exclude_pattern_without_pattern": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.NamedExcludePatterns = []NamedExcludePattern{{Name: "a", Replace: "[A]"}}
+				return cfg
+			}(),
+			wantErr: "named exclude pattern \"a\": pattern cannot be empty",
+		},
+		"invalid_named_exclude_pattern": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.NamedExcludePatterns = []NamedExcludePattern{{Name: "a", Pattern: `(`}}
+				return cfg
+			}(),
+			wantErr:         "invalid named exclude pattern \"a\"",
+			wantErrContains: true,
+		},
+		"secrets": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Secrets = SecretsConfig{Env: []string{"DB_PASSWORD"}, Files: []string{"/run/secrets/api_key"}}
+				return cfg
+			}(),
+		},
+		"empty_secret_env": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Secrets = SecretsConfig{Env: []string{"DB_PASSWORD", ""}}
+				return cfg
+			}(),
+			wantErr: "secret environment variable name cannot be empty",
+		},
+		"empty_secret_file": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Secrets = SecretsConfig{Files: []string{""}}
+				return cfg
+			}(),
+			wantErr: "secret file path cannot be empty",
+		},
+		"entropy": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Entropy = EntropyConfig{Enabled: true, MinLength: 32, MinEntropy: 3, MinCharClasses: 2}
+				return cfg
+			}(),
+		},
+		"negative_entropy_min_length": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Entropy = EntropyConfig{Enabled: true, MinLength: -1}
+				return cfg
+			}(),
+			wantErr: "entropy min length cannot be negative: -1",
+		},
+		"negative_entropy_min_entropy": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Entropy = EntropyConfig{Enabled: true, MinEntropy: -0.5}
+				return cfg
+			}(),
+			wantErr: "entropy min entropy cannot be negative: -0.5",
+		},
+		"invalid_entropy_min_char_classes": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Entropy = EntropyConfig{Enabled: true, MinCharClasses: 4}
+				return cfg
+			}(),
+			wantErr: "invalid entropy min char classes: 4, must be from 1 to 3",
+		},
+		"hash_exclude_patterns": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.ExcludePatternsAction = ActionHash
+				cfg.Encoder.HashKey = "secret"
+				return cfg
+			}(),
+		},
+		"hash_exclude_patterns_without_key": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.ExcludePatternsAction = ActionHash
+				return cfg
+			}(),
+			wantErr: "hash key cannot be empty when exclude patterns action is \"hash\"",
+		},
+		"invalid_exclude_patterns_action": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.ExcludePatternsAction = "drop"
+				return cfg
+			}(),
+			wantErr: "invalid exclude patterns action: \"drop\", must be \"mask\" or \"hash\"",
+		},
+		"omit_by_default": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.DefaultFieldAction = ActionOmit
+				return cfg
+			}(),
+		},
+		"hash_by_default": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.DefaultFieldAction = ActionHash
+				cfg.Encoder.HashKey = "secret"
+				return cfg
+			}(),
+		},
+		"hash_by_default_without_key": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.DefaultFieldAction = ActionHash
+				return cfg
+			}(),
+			wantErr: "hash key cannot be empty when default field action is \"hash\"",
+		},
+		"invalid_default_field_action": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.DefaultFieldAction = "display"
+				return cfg
+			}(),
+			wantErr: "invalid default field action: \"display\", must be \"mask\", \"hash\" or \"omit\"",
+		},
+		"field_rules": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.FieldRules = map[string]string{
+					"github.com/acme/api.User.Email": "display",
+					"main.User.Card":                 "mask=last4",
+					"*.Password":                     "omit",
+				}
+				return cfg
+			}(),
+		},
+		"invalid_field_rule_path": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.FieldRules = map[string]string{"User.Email": "display"}
+				return cfg
+			}(),
+			wantErr: "invalid field rule path \"User.Email\", must be like \"github.com/acme/api.User.Email\" or \"*.Email\"",
+		},
+		"invalid_field_rule_action": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.FieldRules = map[string]string{"*.Email": "show"}
+				return cfg
+			}(),
+			wantErr: "invalid field rule \"*.Email\": unknown action \"show\"",
+		},
+		"limits": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.MaxDepth = 10
+				cfg.Encoder.MaxSliceLength = 100
+				cfg.Encoder.MaxMapEntries = 100
+				cfg.Encoder.MaxStringLength = 1024
+				cfg.Encoder.MaxOutputBytes = 64 * 1024
+				return cfg
+			}(),
+		},
+		"negative_max_depth": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.MaxDepth = -1
+				return cfg
+			}(),
+			wantErr: "max depth cannot be negative: -1",
+		},
+		"negative_max_output_bytes": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.MaxOutputBytes = -10
+				return cfg
+			}(),
+			wantErr: "max output bytes cannot be negative: -10",
+		},
+		"cache": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Cache = CacheConfig{Mode: CacheModeHashed, Size: 1000}
+				return cfg
+			}(),
+		},
+		"invalid_cache_mode": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Cache.Mode = "encrypted"
+				return cfg
+			}(),
+			wantErr: "invalid cache mode: \"encrypted\", must be \"plain\", \"hashed\" or \"disabled\"",
+		},
+		"negative_cache_size": {
+			cfg: func() Config {
+				cfg := DefaultConfig()
+				cfg.Encoder.Cache.Size = -1
+				return cfg
+			}(),
+			wantErr: "cache size cannot be negative: -1",
+		},
 	}
 
 	for name, tt := range tests {
@@ -314,3 +661,17 @@ func TestConfig_ToString(t *testing.T) {
 		"---------------------------------------------------------------------\n"
 	require.EqualValues(t, exp, got)
 }
+
+func TestConfig_ToString_HashKey(t *testing.T) {
+	// GIVEN.
+	cfg := DefaultConfig()
+	cfg.Encoder.HashKey = "super-secret-key"
+
+	// WHEN.
+	got := cfg.ToString()
+
+	// THEN.
+	require.NotContains(t, got, "super-secret-key")
+	require.Contains(t, got, "hash-key: '[CENSORED]'")
+	require.Equal(t, "super-secret-key", cfg.Encoder.HashKey)
+}
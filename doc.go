@@ -30,6 +30,9 @@
  - Customizable configuration: Offers flexibility in configuration through the use of a `.yaml` file or by
    directly passing a `config.Config` struct.
 
+ - Generated code: the censorgen tool (github.com/vpakhuchyi/censor/cmd/censorgen) generates AppendCensoredJSON
+   methods, so structs are encoded in the JSON format without reflection.
+
  Promoted use-case:
 
  Censor is designed to seamlessly integrate with popular loggers.
@@ -79,6 +82,10 @@
  |------------------------|-----------------------------------------------------------------------|
  | Struct                 | By default, all field values are masked.                              |
  |                        | Use the `censor:"display"` tag to override this behavior.             |
+ |                        | Use `censor:"mask=lastN"`, `censor:"mask=firstN"` or                  |
+ |                        | `censor:"mask=email"` to keep a part of the value visible.            |
+ |                        | Use `censor:"hash"` to replace the value with a keyed digest.         |
+ |                        | Use `censor:"omit"` to drop the field from the output.                |
  |                        | Struct/Slice/Array/Pointer/Map/Interface are parsed recursively.      |
  |                        | All unexported fields are ignored.                                    |
  |------------------------|-----------------------------------------------------------------------|
@@ -0,0 +1,340 @@
+package censor
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/vpakhuchyi/censor/internal/encoder"
+)
+
+// JSONAppender is implemented by types with methods generated by the censorgen tool.
+//
+// Processors that use the JSON format call AppendCensoredJSON instead of encoding such structs with reflection.
+// Generated code doesn't track the depth and the size of the output, so it's not used by processors
+// with MaxDepth or MaxOutputBytes limits configured. Functions registered with RegisterType,
+// the Censorer interface and json.Marshaler take precedence over generated code.
+type JSONAppender interface {
+	// AppendCensoredJSON appends a JSON representation of the value with sensitive data masked to dst.
+	// While a value is encoded by a Processor, the given Processor is only meant to be passed to BeginJSONStruct.
+	// It's valid only during the call and must not be retained.
+	AppendCensoredJSON(dst []byte, p *Processor) []byte
+}
+
+var jsonAppenderType = reflect.TypeFor[JSONAppender]()
+
+// callPool contains processors that are passed to generated code during encoding.
+var callPool = sync.Pool{
+	New: func() any {
+		return new(Processor)
+	},
+}
+
+// appendGenerated encodes a value that implements JSONAppender. The generated code receives a processor
+// bound to the given buffer, so values encoded by the generated code with reflection share the state
+// of the current encoding call, e.g. cycle detection.
+func (p *Processor) appendGenerated(b *encoder.Buffer, v reflect.Value) {
+	a, ok := v.Interface().(JSONAppender)
+	if !ok {
+		return
+	}
+
+	call, ok := callPool.Get().(*Processor)
+	if !ok {
+		call = new(Processor)
+	}
+	// Only the encoder and the buffer are needed to write generated code output, so the configuration isn't copied.
+	call.encoder = p.json
+	call.json = p.json
+	call.buf = b
+
+	b.Write(a.AppendCensoredJSON(b.AvailableBuffer(), call))
+
+	*call = Processor{}
+	callPool.Put(call)
+}
+
+// JSONStructWriter writes struct fields in the JSON format. It's used by code generated with censorgen,
+// so the output is the same as the one produced by the Processor with reflection.
+//
+// Every field is written with the Field method. If it returns true, the field value must be written
+// with exactly one of the value methods (String, Int, Value, etc.).
+type JSONStructWriter struct {
+	p *Processor
+	b *encoder.Buffer
+	// typeName is the name of the struct type qualified with its package path.
+	typeName string
+	// dst is a slice the output is appended to once the struct is written. It's used only if owned is true.
+	dst []byte
+	// owned is true if b is taken from the pool by the writer itself.
+	owned bool
+	// written is the number of written fields.
+	written int
+	// field describes the field that is being written.
+	field encoder.Field
+}
+
+// BeginJSONStruct starts writing a struct that is appended to dst. End must be called once all fields are written.
+// The typeName is the name of the struct type qualified with its package path, e.g. "github.com/acme/api.User",
+// which is used to apply field rules.
+func (p *Processor) BeginJSONStruct(dst []byte, typeName string) JSONStructWriter {
+	w := JSONStructWriter{p: p, b: p.buf, typeName: typeName}
+	if w.b == nil {
+		w.b = encoder.GetBuffer()
+		w.dst = dst
+		w.owned = true
+	} else {
+		// dst is a part of the unused capacity of the buffer, so it's written in place.
+		w.b.Write(dst)
+	}
+	w.b.WriteByte('{')
+
+	return w
+}
+
+// End finishes the struct and returns the extended slice.
+func (w *JSONStructWriter) End() []byte {
+	w.b.WriteByte('}')
+
+	if !w.owned {
+		return w.b.AvailableBuffer()
+	}
+
+	dst := append(w.dst, w.b.Bytes()...)
+	encoder.PutBuffer(w.b)
+	w.b = nil
+
+	return dst
+}
+
+// Field writes the name of a struct field and reports whether its value must be written.
+// The name is the Go name of the field, jsonName is the name from its `json` tag (or an empty string)
+// and tag is the value of its `censor` tag. If the field is masked, the mask is written right away.
+func (w *JSONStructWriter) Field(name, jsonName, tag string) bool {
+	goName := name
+	if w.p.json.UseJSONTagName {
+		if jsonName == "-" {
+			return false
+		}
+
+		if jsonName != "" {
+			name = jsonName
+		}
+	}
+
+	action, keep := w.p.json.FieldAction(w.typeName, goName, tag)
+	if action == encoder.ActionOmit {
+		return false
+	}
+
+	if w.written != 0 {
+		w.b.WriteByte(',')
+	}
+	w.written++
+
+	w.b.WriteByte('"')
+	w.b.WriteString(name)
+	w.b.WriteString(`": `)
+
+	w.field = encoder.Field{Name: name, Action: action, Keep: keep}
+	if action == encoder.ActionMask {
+		w.p.json.FieldValue(w.b, w.field, reflect.Value{})
+
+		return false
+	}
+
+	return true
+}
+
+// Value writes a field value of any type.
+func (w *JSONStructWriter) Value(v any) {
+	w.p.json.FieldValue(w.b, w.field, reflect.ValueOf(v))
+}
+
+// ValuePtr writes a field value of an interface type. A pointer to the field is required
+// to preserve its static type, which may have its own encoding rules.
+func (w *JSONStructWriter) ValuePtr(v any) {
+	w.p.json.FieldValue(w.b, w.field, reflect.ValueOf(v).Elem())
+}
+
+// String writes a field value of the string type.
+func (w *JSONStructWriter) String(v string) {
+	if w.p.json.HasPredeclaredTypes() {
+		w.Value(v)
+
+		return
+	}
+
+	w.p.json.FieldString(w.b, w.field, v)
+}
+
+// Bool writes a field value of the bool type.
+func (w *JSONStructWriter) Bool(v bool) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.b.Write(strconv.AppendBool(w.b.AvailableBuffer(), v))
+}
+
+// Int writes a field value of the int type.
+func (w *JSONStructWriter) Int(v int) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeInt(int64(v))
+}
+
+// Int8 writes a field value of the int8 type.
+func (w *JSONStructWriter) Int8(v int8) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeInt(int64(v))
+}
+
+// Int16 writes a field value of the int16 type.
+func (w *JSONStructWriter) Int16(v int16) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeInt(int64(v))
+}
+
+// Int32 writes a field value of the int32 type.
+func (w *JSONStructWriter) Int32(v int32) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeInt(int64(v))
+}
+
+// Int64 writes a field value of the int64 type.
+func (w *JSONStructWriter) Int64(v int64) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeInt(int64(v))
+}
+
+// Uint writes a field value of the uint type.
+func (w *JSONStructWriter) Uint(v uint) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeUint(uint64(v))
+}
+
+// Uint8 writes a field value of the uint8 type.
+func (w *JSONStructWriter) Uint8(v uint8) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeUint(uint64(v))
+}
+
+// Uint16 writes a field value of the uint16 type.
+func (w *JSONStructWriter) Uint16(v uint16) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeUint(uint64(v))
+}
+
+// Uint32 writes a field value of the uint32 type.
+func (w *JSONStructWriter) Uint32(v uint32) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeUint(uint64(v))
+}
+
+// Uint64 writes a field value of the uint64 type.
+func (w *JSONStructWriter) Uint64(v uint64) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeUint(uint64(v))
+}
+
+// Uintptr writes a field value of the uintptr type.
+func (w *JSONStructWriter) Uintptr(v uintptr) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.writeUint(uint64(v))
+}
+
+// Float32 writes a field value of the float32 type.
+func (w *JSONStructWriter) Float32(v float32) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.b.WriteString(decimal.NewFromFloat32(v).String())
+}
+
+// Float64 writes a field value of the float64 type.
+func (w *JSONStructWriter) Float64(v float64) {
+	if !w.plain() {
+		w.Value(v)
+
+		return
+	}
+
+	w.b.WriteString(decimal.NewFromFloat(v).String())
+}
+
+// plain reports whether a value of a predeclared type can be written as is, without reflection.
+func (w *JSONStructWriter) plain() bool {
+	return w.field.Action == encoder.ActionDisplay && !w.p.json.HasPredeclaredTypes()
+}
+
+// writeInt writes a signed integer the same way the encoder does.
+func (w *JSONStructWriter) writeInt(v int64) {
+	w.b.Write(strconv.AppendInt(w.b.AvailableBuffer(), v, 10))
+}
+
+// writeUint writes an unsigned integer the same way the encoder does.
+func (w *JSONStructWriter) writeUint(v uint64) {
+	w.b.Write(strconv.AppendUint(w.b.AvailableBuffer(), v, 10))
+}
@@ -0,0 +1,154 @@
+package censor
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vpakhuchyi/censor/internal/encoder"
+)
+
+// Decisions reported by Processor.Inspect.
+const (
+	// DecisionDisplayed means that the value is written as is.
+	DecisionDisplayed = "displayed"
+	// DecisionMaskedByTag means that a struct field is masked, hashed, partially masked or omitted
+	// according to its censor tag, a field rule or the default field action.
+	DecisionMaskedByTag = "masked-by-tag"
+	// DecisionMaskedByMapKey means that a map value is masked as its key is matched by the mask map keys patterns.
+	DecisionMaskedByMapKey = "masked-by-map-key"
+	// DecisionMaskedByDefault means that a value is masked in the deny-by-default mode.
+	DecisionMaskedByDefault = "masked-by-default"
+	// DecisionMaskedByPattern means that a part of a string is matched by an exclude pattern, a named exclude pattern,
+	// a detector or a known secret.
+	DecisionMaskedByPattern = "masked-by-pattern"
+	// DecisionUnsupportedType means that a value of an unsupported type is replaced with a placeholder.
+	DecisionUnsupportedType = "unsupported-type"
+)
+
+// decisions contains the decisions by reason of masking.
+var decisions = map[encoder.MaskReason]string{
+	"":                            DecisionDisplayed,
+	encoder.ReasonStructDefault:   DecisionMaskedByTag,
+	encoder.ReasonStructTag:       DecisionMaskedByTag,
+	encoder.ReasonFieldRule:       DecisionMaskedByTag,
+	encoder.ReasonMapKey:          DecisionMaskedByMapKey,
+	encoder.ReasonDenyByDefault:   DecisionMaskedByDefault,
+	encoder.ReasonExcludePattern:  DecisionMaskedByPattern,
+	encoder.ReasonNamedPattern:    DecisionMaskedByPattern,
+	encoder.ReasonDetector:        DecisionMaskedByPattern,
+	encoder.ReasonSecret:          DecisionMaskedByPattern,
+	encoder.ReasonUnsupportedType: DecisionUnsupportedType,
+}
+
+// ReportEntry describes how a single value is written to the output.
+type ReportEntry struct {
+	// Path is the path of the value, the same as MaskEvent.Path.
+	Path string
+	// Decision describes how the value is written, e.g. DecisionDisplayed.
+	Decision string
+	// Reason is the reason of masking, the same as MaskEvent.Reason. It's empty for displayed values.
+	Reason string
+	// Rule identifies the rule that caused the masking, the same as MaskEvent.Rule.
+	Rule string
+	// Type is the type of the value, the same as MaskEvent.Type.
+	Type reflect.Type
+}
+
+// Report describes how every value reachable from an inspected value is written to the output,
+// see Processor.Inspect.
+type Report struct {
+	// Entries are listed in the order of encoding. A path may have several entries, e.g. a string
+	// with several matches of patterns or elements of a slice.
+	Entries []ReportEntry
+}
+
+// Lookup returns the entries of the given path.
+func (r Report) Lookup(path string) []ReportEntry {
+	var entries []ReportEntry
+	for _, e := range r.Entries {
+		if e.Path == path {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Filter returns the entries with the given decision.
+func (r Report) Filter(decision string) []ReportEntry {
+	var entries []ReportEntry
+	for _, e := range r.Entries {
+		if e.Decision == decision {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Inspect walks the given value exactly like Any, but instead of producing the output, it returns a report
+// of how every value is written: displayed, masked by a tag, masked by a pattern, etc. Masked values are not walked
+// further. The report never contains the values themselves and doesn't depend on the output format.
+//
+// It's useful for tests, e.g. to check that no field containing a card number is displayed,
+// and for building an inventory of the data classification. Inspect is much slower than Any.
+func (p *Processor) Inspect(val any) Report {
+	events := encoder.Inspect(p.inspector.get(p), reflect.ValueOf(val))
+
+	r := Report{Entries: make([]ReportEntry, 0, len(events))}
+	for _, e := range events {
+		r.Entries = append(r.Entries, ReportEntry{
+			Path:     e.Path,
+			Decision: decisions[e.Reason],
+			Reason:   string(e.Reason),
+			Rule:     e.Rule,
+			Type:     e.Type,
+		})
+	}
+
+	return r
+}
+
+// inspector holds the encoder used by Processor.Inspect. It's created on the first call,
+// since it's rarely used and reports every value, which makes it slower than the regular encoders.
+type inspector struct {
+	mu  sync.Mutex
+	enc atomic.Pointer[encoder.JSONEncoder]
+}
+
+// get returns the encoder, creating it with the configuration, the registered types and secrets of p if needed.
+func (i *inspector) get(p *Processor) *encoder.JSONEncoder {
+	if enc := i.enc.Load(); enc != nil {
+		return enc
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if enc := i.enc.Load(); enc != nil {
+		return enc
+	}
+
+	cfg := p.cfg.Encoder.toEncoderConfig()
+	cfg.Inspect = true
+	enc := encoder.NewJSONEncoder(cfg)
+	for t, fn := range p.json.RegisteredTypes() {
+		enc.RegisterType(t, fn)
+	}
+	enc.RegisterSecrets(p.json.RegisteredSecrets()...)
+	i.enc.Store(enc)
+
+	return enc
+}
+
+// register calls fn with the encoder if it's already created. Otherwise, the registered types and secrets
+// are copied when it's created.
+func (i *inspector) register(fn func(e *encoder.JSONEncoder)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if enc := i.enc.Load(); enc != nil {
+		fn(enc)
+	}
+}
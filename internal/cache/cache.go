@@ -57,3 +57,31 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 
 	return value, found
 }
+
+// Delete removes the given key from the cache.
+func (c *Cache[T]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.cache[key]; !found {
+		return
+	}
+
+	delete(c.cache, key)
+	for el := c.keys.Front(); el != nil; el = el.Next() {
+		if el.Value == key {
+			c.keys.Remove(el)
+
+			break
+		}
+	}
+}
+
+// Reset removes all the key-value pairs from the cache.
+func (c *Cache[T]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.keys.Init()
+	clear(c.cache)
+}
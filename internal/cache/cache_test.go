@@ -75,3 +75,42 @@ func TestCache_Set(t *testing.T) {
 		require.Len(t, c.cache, 1)
 	})
 }
+
+func TestCache_Delete(t *testing.T) {
+	// GIVEN
+	c := New[string](10)
+	c.Set("key1", "value1")
+	c.Set("key2", "value2")
+
+	// WHEN
+	c.Delete("key1")
+	c.Delete("unknown")
+
+	// THEN
+	_, found1 := c.Get("key1")
+	got2, found2 := c.Get("key2")
+	require.False(t, found1)
+	require.True(t, found2)
+	require.Equal(t, "value2", got2)
+	require.Equal(t, 1, c.keys.Len())
+	require.Len(t, c.cache, 1)
+}
+
+func TestCache_Reset(t *testing.T) {
+	// GIVEN
+	c := New[string](10)
+	c.Set("key1", "value1")
+	c.Set("key2", "value2")
+
+	// WHEN
+	c.Reset()
+
+	// THEN
+	_, found := c.Get("key1")
+	require.False(t, found)
+	require.Equal(t, 0, c.keys.Len())
+	require.Empty(t, c.cache)
+
+	c.Set("key1", "value1")
+	require.Equal(t, 1, c.keys.Len())
+}
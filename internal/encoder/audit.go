@@ -0,0 +1,262 @@
+package encoder
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// MaskReason describes why a value was masked.
+type MaskReason string
+
+const (
+	// ReasonStructDefault means that a struct field without a censor tag got DefaultFieldAction.
+	ReasonStructDefault MaskReason = "struct-default"
+	// ReasonStructTag means that a struct field was masked according to its censor tag.
+	ReasonStructTag MaskReason = "struct-tag"
+	// ReasonFieldRule means that a struct field was masked according to a field rule.
+	ReasonFieldRule MaskReason = "field-rule"
+	// ReasonMapKey means that a map value or an object member was masked as its key is matched by MaskMapKeys.
+	ReasonMapKey MaskReason = "map-key"
+	// ReasonDenyByDefault means that a value was masked in the deny-by-default mode.
+	ReasonDenyByDefault MaskReason = "deny-by-default"
+	// ReasonExcludePattern means that a part of a string was matched by ExcludePatterns.
+	ReasonExcludePattern MaskReason = "exclude-pattern"
+	// ReasonNamedPattern means that a part of a string was matched by one of NamedPatterns.
+	ReasonNamedPattern MaskReason = "named-pattern"
+	// ReasonDetector means that a part of a string was found by one of the built-in detectors
+	// or the entropy detector.
+	ReasonDetector MaskReason = "detector"
+	// ReasonSecret means that a string contains a value registered with RegisterSecrets.
+	ReasonSecret MaskReason = "secret"
+	// ReasonUnsupportedType means that a value of an unsupported type (e.g. a channel or a function)
+	// was replaced with a placeholder.
+	ReasonUnsupportedType MaskReason = "unsupported-type"
+)
+
+// entropyDetectorName is the rule of events reported for tokens found by the entropy detector.
+const entropyDetectorName = "entropy"
+
+// stringType is the type of events reported for parts of strings.
+var stringType = reflect.TypeFor[string]()
+
+// MaskEvent describes a value that was masked, hashed, partially masked or omitted. It never contains the value.
+type MaskEvent struct {
+	// Path is the path of the value, e.g. "User.Address.Street". It starts with the name of the encoded struct type,
+	// struct fields are referred to by their Go names, and elements of slices, arrays and maps are denoted with "[]",
+	// so map keys never get into the path. Members of embedded JSON documents are referred to by their keys.
+	Path string
+	// Reason describes why the value was masked.
+	Reason MaskReason
+	// Rule identifies the rule that caused the masking: the path of a field rule (ReasonFieldRule),
+	// the name of a named pattern (ReasonNamedPattern) or the name of a detector (ReasonDetector).
+	// It's empty for other reasons.
+	Rule string
+	// Type is the type of the value. For parts of strings matched by patterns, detectors or secrets,
+	// it's always the string type. It's nil for members of embedded JSON documents masked by their keys.
+	Type reflect.Type
+}
+
+// report calls the OnMask hook with an event of the value at the current path extended with the given segment.
+// In the inspection mode, the event is added to the inspection of b instead. Otherwise, it does nothing
+// if no hook is configured.
+func (e *baseEncoder) report(b *Buffer, segment string, reason MaskReason, rule string, t reflect.Type) {
+	if !e.audited {
+		return
+	}
+
+	event := MaskEvent{Path: string(b.path) + segment, Reason: reason, Rule: rule, Type: t}
+	if e.inspect {
+		b.inspection = append(b.inspection, event)
+
+		return
+	}
+
+	e.onMask(event)
+}
+
+// reportMatches reports the given matches of a string, see findMatches.
+func (e *baseEncoder) reportMatches(b *Buffer, matches []match) {
+	for _, m := range matches {
+		switch {
+		case m.secret:
+			e.report(b, "", ReasonSecret, "", stringType)
+		case m.by == nil:
+			e.report(b, "", ReasonExcludePattern, "", stringType)
+		default:
+			e.report(b, "", m.by.reason, m.by.name, stringType)
+		}
+	}
+}
+
+// pushPath extends the current path with the given segment if the OnMask hook is configured
+// or the encoder is created in the inspection mode.
+// It returns the previous length of the path, which must be passed to Buffer.popPath once the value is encoded.
+func (e *baseEncoder) pushPath(b *Buffer, segment string) int {
+	n := len(b.path)
+	if e.audited {
+		b.path = append(b.path, segment...)
+	}
+
+	return n
+}
+
+// pushKey is the same as pushPath, but for members of JSON objects.
+func (e *baseEncoder) pushKey(b *Buffer, key string) int {
+	n := len(b.path)
+	if e.audited {
+		b.path = append(b.path, '.')
+		b.path = append(b.path, key...)
+	}
+
+	return n
+}
+
+// pushStruct is the same as pushPath, but the path is started with the given struct type name
+// only if the struct is the encoded value itself.
+func (e *baseEncoder) pushStruct(b *Buffer, name string) int {
+	if len(b.path) != 0 {
+		return len(b.path)
+	}
+
+	return e.pushPath(b, name)
+}
+
+// popPath restores the path of the value being encoded, see baseEncoder.pushPath.
+func (b *Buffer) popPath(n int) {
+	b.path = b.path[:n]
+}
+
+// reportedMask wraps the given mask function of values of the given type, so masked values are reported
+// with the given reason. It returns the mask function as is if no hook is configured.
+func (e *baseEncoder) reportedMask(t reflect.Type, reason MaskReason, mask func(b *Buffer)) func(b *Buffer) {
+	if !e.audited {
+		return mask
+	}
+
+	return func(b *Buffer) {
+		e.report(b, "", reason, "", t)
+		mask(b)
+	}
+}
+
+// fieldReason returns the reason of the action of a struct field (see FieldAction)
+// along with the path of the field rule that sets it, if any.
+func (e *baseEncoder) fieldReason(typeName, field, tag string) (MaskReason, string) {
+	if _, ok := e.fieldRules.get(typeName, field); ok {
+		if _, ok = e.fieldRules[fieldRuleKey{typ: typeName, field: field}]; ok && typeName != "" {
+			return ReasonFieldRule, typeName + "." + field
+		}
+
+		return ReasonFieldRule, anyType + "." + field
+	}
+
+	if tag == "" {
+		return ReasonStructDefault, ""
+	}
+
+	return ReasonStructTag, ""
+}
+
+// structAudit contains what is needed to report masked fields of a struct type.
+// It's nil if values aren't reported.
+type structAudit struct {
+	// name is the name of the struct type, which starts the path of the encoded struct.
+	name string
+	// segments contains path segments of the fields, e.g. ".Email".
+	segments []string
+	reasons  []MaskReason
+	rules    []string
+}
+
+// newStructAudit returns a structAudit for the given struct type.
+func (e *baseEncoder) newStructAudit(t reflect.Type) *structAudit {
+	if !e.audited {
+		return nil
+	}
+
+	typeName := TypeName(t)
+	a := &structAudit{
+		name:     t.Name(),
+		segments: make([]string, t.NumField()),
+		reasons:  make([]MaskReason, t.NumField()),
+		rules:    make([]string, t.NumField()),
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		a.segments[i] = "." + field.Name
+		a.reasons[i], a.rules[i] = e.fieldReason(typeName, field.Name, field.Tag.Get(e.CensorFieldTag))
+	}
+
+	return a
+}
+
+// enter starts the path of the struct if needed, see baseEncoder.pushStruct.
+func (a *structAudit) enter(e *baseEncoder, b *Buffer) int {
+	if a == nil {
+		return len(b.path)
+	}
+
+	return e.pushStruct(b, a.name)
+}
+
+// push extends the current path with the i-th field.
+func (a *structAudit) push(e *baseEncoder, b *Buffer, i int) int {
+	if a == nil {
+		return len(b.path)
+	}
+
+	return e.pushPath(b, a.segments[i])
+}
+
+// report reports the i-th field of the given value that is not displayed.
+func (a *structAudit) report(e *baseEncoder, b *Buffer, v reflect.Value, i int) {
+	if a == nil {
+		return
+	}
+
+	e.report(b, a.segments[i], a.reasons[i], a.rules[i], v.Type().Field(i).Type)
+}
+
+// stringsCacheMode returns the mode of the cache of censored strings. With the OnMask hook or in the inspection mode,
+// the cache is disabled, so every match is reported.
+func stringsCacheMode(c Config) CacheMode {
+	if c.OnMask != nil || c.Inspect {
+		return CacheDisabled
+	}
+
+	return c.CacheMode
+}
+
+// Inspect encodes the given value with the given encoder, which must be created with Config.Inspect,
+// and returns the events of all its values instead of the output. Unlike the events passed to the OnMask hook,
+// they include displayed values, which have an empty reason.
+func Inspect(e Encoder, v reflect.Value) []MaskEvent {
+	// The output is discarded as soon as it grows large enough, so large values don't take a lot of memory.
+	b := NewWriterBuffer(io.Discard, new(bytes.Buffer))
+	e.Encode(b, v)
+
+	return b.inspection
+}
+
+// inspected wraps the given encoder function of a scalar type, so values that aren't reported
+// as masked by it are reported as displayed. Only encoders created with Config.Inspect report displayed values.
+func (e *baseEncoder) inspected(t reflect.Type, next encoderFunc) encoderFunc {
+	if !e.inspect {
+		return next
+	}
+
+	return func(b *Buffer, v reflect.Value) {
+		n := len(b.inspection)
+		next(b, v)
+		e.reportDisplayed(b, n, t)
+	}
+}
+
+// reportDisplayed reports the value of the given type at the current path as displayed in the inspection mode,
+// unless anything was reported since the inspection had n events.
+func (e *baseEncoder) reportDisplayed(b *Buffer, n int, t reflect.Type) {
+	if e.inspect && len(b.inspection) == n {
+		b.inspection = append(b.inspection, MaskEvent{Path: string(b.path), Type: t})
+	}
+}
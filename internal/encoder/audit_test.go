@@ -0,0 +1,216 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type auditAddress struct {
+	City   string `censor:"display"`
+	Street string
+}
+
+type auditUser struct {
+	Name    string `censor:"display"`
+	Email   string `censor:"mask"`
+	Token   string `censor:"omit"`
+	Phone   string
+	Address auditAddress      `censor:"display"`
+	Notes   []string          `censor:"display"`
+	Meta    map[string]string `censor:"display"`
+	Raw     json.RawMessage   `censor:"display"`
+}
+
+func TestEncoder_OnMask(t *testing.T) {
+	stringType := reflect.TypeFor[string]()
+	val := auditUser{
+		Name:    "John",
+		Address: auditAddress{City: "Kyiv", Street: "Khreshchatyk"},
+		Notes:   []string{"ok", "mail me at john@example.com"},
+		Meta:    map[string]string{"password": "hunter2"},
+		Raw:     json.RawMessage(`{"user": {"password": "hunter2", "note": "hunter2"}}`),
+	}
+
+	tests := map[string]struct {
+		cfg Config
+		val any
+		exp []MaskEvent
+	}{
+		"struct_fields_and_strings": {
+			cfg: Config{
+				MaskValue:   "[CENSORED]",
+				Detectors:   []string{"email"},
+				MaskMapKeys: []string{"(?i)password"},
+				FieldRules:  map[string]string{"*.Phone": "mask=last4"},
+			},
+			val: val,
+			exp: []MaskEvent{
+				{Path: "auditUser.Email", Reason: ReasonStructTag, Type: stringType},
+				{Path: "auditUser.Token", Reason: ReasonStructTag, Type: stringType},
+				{Path: "auditUser.Phone", Reason: ReasonFieldRule, Rule: "*.Phone", Type: stringType},
+				{Path: "auditUser.Address.Street", Reason: ReasonStructDefault, Type: stringType},
+				{Path: "auditUser.Notes[]", Reason: ReasonDetector, Rule: "email", Type: stringType},
+				{Path: "auditUser.Meta[]", Reason: ReasonMapKey, Type: stringType},
+				{Path: "auditUser.Raw.user.password", Reason: ReasonMapKey},
+				{Path: "auditUser.Raw.user.note", Reason: ReasonSecret, Type: stringType},
+			},
+		},
+		"patterns_and_secrets": {
+			cfg: Config{
+				MaskValue:       "[CENSORED]",
+				ExcludePatterns: []string{`\d{4}`},
+				NamedPatterns:   []NamedPattern{{Name: "order", Pattern: `order-\w+`}},
+				Entropy:         &EntropyConfig{MinLength: 20, MinEntropy: 3.5, MinCharClasses: 3},
+			},
+			val: []string{"pin 1234", "order-abc", "key aB3dE5fG7hJ9kL1mN3pQ", "hunter2"},
+			exp: []MaskEvent{
+				{Path: "[]", Reason: ReasonExcludePattern, Type: stringType},
+				{Path: "[]", Reason: ReasonNamedPattern, Rule: "order", Type: stringType},
+				{Path: "[]", Reason: ReasonDetector, Rule: "entropy", Type: stringType},
+				{Path: "[]", Reason: ReasonSecret, Type: stringType},
+			},
+		},
+		"deny_by_default": {
+			cfg: Config{
+				MaskValue:      "[CENSORED]",
+				DenyByDefault:  true,
+				DisplayMapKeys: []string{"^id$"},
+			},
+			val: map[string]any{"id": 1, "name": "John"},
+			exp: []MaskEvent{
+				{Path: "[]", Reason: ReasonDenyByDefault, Type: reflect.TypeFor[any]()},
+			},
+		},
+		"loose_scalar": {
+			cfg: Config{MaskValue: "[CENSORED]", DenyByDefault: true},
+			val: 42,
+			exp: []MaskEvent{
+				{Reason: ReasonDenyByDefault, Type: reflect.TypeFor[int]()},
+			},
+		},
+	}
+
+	encoders := map[string]func(c Config) Encoder{
+		"json": func(c Config) Encoder { return NewJSONEncoder(c) },
+		"text": func(c Config) Encoder { return NewTextEncoder(c) },
+	}
+
+	for name, tt := range tests {
+		for format, newEncoder := range encoders {
+			t.Run(name+"_"+format, func(t *testing.T) {
+				// GIVEN.
+				var got []MaskEvent
+				tt.cfg.OnMask = func(e MaskEvent) { got = append(got, e) }
+				e := newEncoder(tt.cfg)
+				e.RegisterSecrets("hunter2")
+
+				// WHEN.
+				e.Encode(NewBuffer(new(bytes.Buffer)), reflect.ValueOf(tt.val))
+
+				// THEN.
+				require.ElementsMatch(t, tt.exp, got)
+			})
+		}
+	}
+}
+
+func TestEncoder_OnMask_Repeated(t *testing.T) {
+	// GIVEN.
+	var (
+		mu  sync.Mutex
+		got int
+	)
+	e := NewJSONEncoder(Config{
+		MaskValue:       "[CENSORED]",
+		ExcludePatterns: []string{`\d{4}`},
+		OnMask: func(MaskEvent) {
+			mu.Lock()
+			got++
+			mu.Unlock()
+		},
+	})
+
+	// WHEN.
+	for range 3 {
+		e.Encode(NewBuffer(new(bytes.Buffer)), reflect.ValueOf("pin 1234"))
+	}
+
+	// THEN.
+	// Censored strings are not cached with the hook, so every match is reported.
+	require.Equal(t, 3, got)
+}
+
+func TestEncoder_OnMask_NoHook(t *testing.T) {
+	// GIVEN.
+	e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+	b := NewBuffer(new(bytes.Buffer))
+
+	// WHEN.
+	e.Encode(b, reflect.ValueOf(auditUser{Notes: []string{"a"}}))
+
+	// THEN.
+	require.Empty(t, b.path)
+	require.Nil(t, e.newStructAudit(reflect.TypeFor[auditUser]()))
+}
+
+func TestInspect(t *testing.T) {
+	type payment struct {
+		ID     string `censor:"display"`
+		Amount int    `censor:"display"`
+		PAN    string
+		Note   string            `censor:"display"`
+		Tags   map[string]string `censor:"display"`
+		Hook   func()            `censor:"display"`
+		Raw    json.RawMessage   `censor:"display"`
+	}
+
+	stringType := reflect.TypeFor[string]()
+
+	// GIVEN.
+	e := NewJSONEncoder(Config{
+		MaskValue:       "[CENSORED]",
+		ExcludePatterns: []string{`\d{4}`},
+		Inspect:         true,
+	})
+	val := payment{
+		ID:   "abc",
+		PAN:  "4111111111111111",
+		Note: "pin 1234",
+		Tags: map[string]string{"env": "prod"},
+		Raw:  json.RawMessage(`{"ok": true}`),
+	}
+
+	// WHEN.
+	got := Inspect(e, reflect.ValueOf(val))
+
+	// THEN.
+	require.Equal(t, []MaskEvent{
+		{Path: "payment.ID", Type: stringType},
+		{Path: "payment.Amount", Type: reflect.TypeFor[int]()},
+		{Path: "payment.PAN", Reason: ReasonStructDefault, Type: stringType},
+		{Path: "payment.Note", Reason: ReasonExcludePattern, Type: stringType},
+		{Path: "payment.Tags[]", Type: stringType},
+		{Path: "payment.Hook", Reason: ReasonUnsupportedType, Type: reflect.TypeFor[func()]()},
+		{Path: "payment.Raw.ok", Type: reflect.TypeFor[bool]()},
+	}, got)
+}
+
+func TestInspect_MaxStringLength(t *testing.T) {
+	for name, e := range map[string]Encoder{
+		"json": NewJSONEncoder(Config{MaskValue: "[CENSORED]", ExcludePatterns: []string{`\d{16}`}, MaxStringLength: 10, Inspect: true}),
+		"text": NewTextEncoder(Config{MaskValue: "[CENSORED]", ExcludePatterns: []string{`\d{16}`}, MaxStringLength: 10, Inspect: true}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// WHEN.
+			got := Inspect(e, reflect.ValueOf([]string{"card 4111111111111111"}))
+
+			// THEN.
+			require.Equal(t, []MaskEvent{{Path: "[]", Reason: ReasonExcludePattern, Type: stringType}}, got)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package encoder
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"sync"
+)
+
+const (
+	// refsCapacity is the number of references that can be tracked without additional allocations.
+	refsCapacity = 8
+	// flushSize is the number of buffered bytes after which a Buffer created with NewWriterBuffer
+	// writes its content to the underlying writer.
+	flushSize = 4096
+	// maxPooledSize is the capacity above which buffers are not returned to the pool,
+	// so a single large value doesn't keep a lot of memory reserved.
+	maxPooledSize = 64 << 10
+)
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return NewBuffer(new(bytes.Buffer))
+	},
+}
+
+// Buffer is an output buffer that also carries the state of a single encoding call.
+// It's not safe for concurrent use, so a new Buffer must be used for every encoding call.
+type Buffer struct {
+	*bytes.Buffer
+	// w is a writer the buffered output is flushed to. It's nil if the output is kept in memory.
+	w io.Writer
+	// flushed is the number of bytes written to w.
+	flushed int64
+	// err is the first error returned by w. Once it's set, the output is discarded.
+	err error
+	// start is the length of the underlying buffer at the moment the Buffer was created.
+	start int
+	// depth is the number of structs, maps, slices and arrays on the current encoding path.
+	depth int
+	// shown reports whether the value being encoded is reached through a displayed struct field or map key.
+	// It's used only in the deny-by-default mode, where other scalars are masked.
+	shown bool
+	// path is the path of the value being encoded, e.g. "User.Address". It's tracked only with the OnMask hook.
+	path []byte
+	// inspection contains events of all encoded values. It's used only by encoders in the inspection mode.
+	inspection []MaskEvent
+	// refs contains pointers, maps and slices on the current encoding path.
+	// It's used to detect values that refer to themselves.
+	refs    []ref
+	refsBuf [refsCapacity]ref
+}
+
+// ref identifies a pointer, map or slice value.
+type ref struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// NewBuffer returns a new Buffer that writes to the given bytes.Buffer.
+func NewBuffer(b *bytes.Buffer) *Buffer {
+	buf := &Buffer{Buffer: b, start: b.Len()}
+	buf.refs = buf.refsBuf[:0]
+
+	return buf
+}
+
+// GetBuffer returns an empty Buffer from the pool. It must be returned with PutBuffer once
+// its content is no longer used.
+func GetBuffer() *Buffer {
+	b, ok := bufferPool.Get().(*Buffer)
+	if !ok {
+		b = NewBuffer(new(bytes.Buffer))
+	}
+
+	return b
+}
+
+// PutBuffer resets the given Buffer and puts it back into the pool.
+// Neither the Buffer nor the slices returned by its Bytes method may be used after that.
+func PutBuffer(b *Buffer) {
+	if b.Cap() > maxPooledSize {
+		return
+	}
+
+	b.Reset()
+	b.w, b.flushed, b.err = nil, 0, nil
+	b.start, b.depth, b.shown = 0, 0, false
+	b.path = b.path[:0]
+	b.inspection = nil
+	clear(b.refsBuf[:])
+	b.refs = b.refsBuf[:0]
+	bufferPool.Put(b)
+}
+
+// NewWriterBuffer returns a new Buffer that uses the given bytes.Buffer as an intermediate buffer
+// and writes its content to w as soon as it grows large enough. This way, the whole output
+// is never kept in memory. The given bytes.Buffer is reset. Flush must be called once encoding is done.
+func NewWriterBuffer(w io.Writer, b *bytes.Buffer) *Buffer {
+	b.Reset()
+	buf := NewBuffer(b)
+	buf.w = w
+
+	return buf
+}
+
+// Flush writes all buffered output to the underlying writer.
+// It returns the total number of bytes written to the writer and the first error that occurred.
+// For a Buffer created with NewBuffer, it does nothing.
+func (b *Buffer) Flush() (int64, error) {
+	if b.w != nil {
+		b.write()
+	}
+
+	return b.flushed, b.err
+}
+
+// flush writes buffered output to the underlying writer if there is enough of it.
+// It must be called only between values, as encoders may read back what they have just written.
+func (b *Buffer) flush() {
+	if b.w != nil && b.Len() >= flushSize {
+		b.write()
+	}
+}
+
+// write moves the buffered output to the underlying writer.
+// After the first error, the output is discarded, so the memory usage stays bounded.
+func (b *Buffer) write() {
+	if b.err == nil {
+		n, err := b.w.Write(b.Bytes())
+		b.flushed += int64(n)
+		b.err = err
+	}
+	b.Reset()
+}
+
+// enter adds the given pointer, map or slice to the current encoding path.
+// It returns false if the value is already on the path, which means that it refers to itself
+// and encoding it would never end. In such a case, the value must not be encoded.
+// Every successful call must be followed by a leave call once the value is encoded.
+func (b *Buffer) enter(v reflect.Value) bool {
+	r := ref{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		// Slices that share the same underlying array but have different lengths are different values.
+		r.len = v.Len()
+	}
+
+	for _, seen := range b.refs {
+		if seen == r {
+			return false
+		}
+	}
+	b.refs = append(b.refs, r)
+
+	return true
+}
+
+// leave removes the last value added by enter from the current encoding path.
+func (b *Buffer) leave() {
+	b.refs = b.refs[:len(b.refs)-1]
+}
+
+// written returns the number of bytes written to the Buffer, including already flushed ones.
+func (b *Buffer) written() int {
+	return int(b.flushed) + b.Len() - b.start
+}
+
+// descend increases the current depth. It returns false if the new depth exceeds the given limit,
+// in such a case the depth stays unchanged. A limit less than or equal to zero means no limit.
+// Every successful call must be followed by an ascend call once the value is encoded.
+func (b *Buffer) descend(limit int) bool {
+	if limit > 0 && b.depth >= limit {
+		return false
+	}
+	b.depth++
+
+	return true
+}
+
+// ascend decreases the current depth.
+func (b *Buffer) ascend() {
+	b.depth--
+}
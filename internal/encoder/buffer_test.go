@@ -0,0 +1,218 @@
+package encoder
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer_enter(t *testing.T) {
+	t.Run("same pointer", func(t *testing.T) {
+		// GIVEN.
+		b := NewBuffer(&bytes.Buffer{})
+		v := reflect.ValueOf(new(int))
+
+		// WHEN, THEN.
+		require.True(t, b.enter(v))
+		require.False(t, b.enter(v))
+		b.leave()
+		require.True(t, b.enter(v))
+	})
+
+	t.Run("same address with a different type", func(t *testing.T) {
+		// GIVEN.
+		b := NewBuffer(&bytes.Buffer{})
+		s := &struct{ A int }{}
+
+		// WHEN, THEN.
+		require.True(t, b.enter(reflect.ValueOf(s)))
+		require.True(t, b.enter(reflect.ValueOf(&s.A)))
+	})
+
+	t.Run("slices with a different length", func(t *testing.T) {
+		// GIVEN.
+		b := NewBuffer(&bytes.Buffer{})
+		s := []int{1, 2, 3}
+
+		// WHEN, THEN.
+		require.True(t, b.enter(reflect.ValueOf(s)))
+		require.True(t, b.enter(reflect.ValueOf(s[:2])))
+		require.False(t, b.enter(reflect.ValueOf(s[:2])))
+	})
+
+	t.Run("deep path", func(t *testing.T) {
+		// GIVEN.
+		b := NewBuffer(&bytes.Buffer{})
+		values := make([]reflect.Value, refsCapacity*2)
+		for i := range values {
+			values[i] = reflect.ValueOf(new(int))
+		}
+
+		// WHEN, THEN.
+		for _, v := range values {
+			require.True(t, b.enter(v))
+		}
+		for _, v := range values {
+			require.False(t, b.enter(v))
+		}
+	})
+}
+
+func TestBuffer_descend(t *testing.T) {
+	// GIVEN.
+	b := NewBuffer(&bytes.Buffer{})
+
+	// WHEN, THEN.
+	require.True(t, b.descend(2))
+	require.True(t, b.descend(2))
+	require.False(t, b.descend(2))
+	b.ascend()
+	require.True(t, b.descend(2))
+	require.True(t, b.descend(0))
+}
+
+func TestBuffer_Flush(t *testing.T) {
+	t.Run("writer", func(t *testing.T) {
+		// GIVEN.
+		var w bytes.Buffer
+		b := NewWriterBuffer(&w, bytes.NewBufferString("stale"))
+		b.WriteString(strings.Repeat("a", flushSize))
+		b.flush()
+		b.WriteString("b")
+
+		// WHEN.
+		n, err := b.Flush()
+
+		// THEN.
+		require.NoError(t, err)
+		require.Equal(t, int64(flushSize+1), n)
+		require.Equal(t, strings.Repeat("a", flushSize)+"b", w.String())
+		require.Equal(t, 0, b.Len())
+		require.Equal(t, flushSize+1, b.written())
+	})
+
+	t.Run("small output is not flushed before Flush", func(t *testing.T) {
+		// GIVEN.
+		var w bytes.Buffer
+		b := NewWriterBuffer(&w, &bytes.Buffer{})
+		b.WriteString("small")
+
+		// WHEN.
+		b.flush()
+
+		// THEN.
+		require.Empty(t, w.String())
+		require.Equal(t, 5, b.written())
+	})
+
+	t.Run("writer error", func(t *testing.T) {
+		// GIVEN.
+		b := NewWriterBuffer(errWriter{}, &bytes.Buffer{})
+		b.WriteString(strings.Repeat("a", flushSize))
+		b.flush()
+		b.WriteString("b")
+
+		// WHEN.
+		n, err := b.Flush()
+
+		// THEN.
+		require.ErrorIs(t, err, errWrite)
+		require.Zero(t, n)
+		require.Equal(t, 0, b.Len())
+	})
+
+	t.Run("without writer", func(t *testing.T) {
+		// GIVEN.
+		b := NewBuffer(&bytes.Buffer{})
+		b.WriteString(strings.Repeat("a", flushSize))
+		b.flush()
+
+		// WHEN.
+		n, err := b.Flush()
+
+		// THEN.
+		require.NoError(t, err)
+		require.Zero(t, n)
+		require.Equal(t, flushSize, b.Len())
+	})
+}
+
+var errWrite = errors.New("write failed")
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestEncoder_WriterBuffer(t *testing.T) {
+	type item struct {
+		ID   int    `censor:"display"`
+		Name string `censor:"display"`
+		Pass string
+	}
+
+	value := make([]item, 1000)
+	for i := range value {
+		value[i] = item{ID: i, Name: "name", Pass: "secret"}
+	}
+
+	encoders := map[string]Encoder{
+		"json": NewJSONEncoder(Config{MaskValue: "[CENSORED]"}),
+		"text": NewTextEncoder(Config{MaskValue: "[CENSORED]"}),
+	}
+
+	for name, e := range encoders {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			var exp bytes.Buffer
+			e.Encode(NewBuffer(&exp), reflect.ValueOf(value))
+
+			var w bytes.Buffer
+			b := NewWriterBuffer(&w, &bytes.Buffer{})
+
+			// WHEN.
+			e.Encode(b, reflect.ValueOf(value))
+			n, err := b.Flush()
+
+			// THEN.
+			require.NoError(t, err)
+			require.Equal(t, exp.String(), w.String())
+			require.Equal(t, int64(exp.Len()), n)
+		})
+	}
+}
+
+func TestPutBuffer(t *testing.T) {
+	t.Run("state is reset", func(t *testing.T) {
+		// GIVEN.
+		b := GetBuffer()
+		b.WriteString("value")
+		b.descend(0)
+		b.enter(reflect.ValueOf(new(int)))
+
+		// WHEN.
+		PutBuffer(b)
+
+		// THEN.
+		require.Equal(t, 0, b.Len())
+		require.Equal(t, 0, b.depth)
+		require.Empty(t, b.refs)
+		require.Equal(t, 0, b.written())
+	})
+
+	t.Run("large buffer is not reused", func(t *testing.T) {
+		// GIVEN.
+		b := GetBuffer()
+		b.Grow(maxPooledSize + 1)
+		b.WriteString("value")
+
+		// WHEN.
+		PutBuffer(b)
+
+		// THEN.
+		require.Equal(t, "value", b.String())
+	})
+}
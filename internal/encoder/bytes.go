@@ -0,0 +1,142 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// BytesFormat describes how byte slices are written to the output.
+type BytesFormat uint8
+
+const (
+	// BytesNumbers writes byte slices as lists of numbers, the same way as other slices.
+	// It's the default format.
+	BytesNumbers BytesFormat = iota
+	// BytesBase64 writes byte slices as standard base64 strings.
+	BytesBase64
+	// BytesHex writes byte slices as hexadecimal strings.
+	BytesHex
+	// BytesString writes byte slices as strings that are checked against the exclude patterns.
+	BytesString
+	// BytesLength writes only the length of byte slices, e.g. "(5 bytes)".
+	BytesLength
+)
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// isBytes reports whether the given type is a byte slice.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// bytesString returns a representation of the given bytes in one of the formats that are not checked
+// against the exclude patterns: BytesBase64, BytesHex or BytesLength.
+func bytesString(f BytesFormat, p []byte) string {
+	switch f {
+	case BytesBase64:
+		return base64.StdEncoding.EncodeToString(p)
+	case BytesHex:
+		return hex.EncodeToString(p)
+	default:
+		return "(" + strconv.Itoa(len(p)) + " bytes)"
+	}
+}
+
+// compileBytes returns an encoder function for byte slices of the given type if they aren't written
+// as lists of numbers. Otherwise, it returns nil. json.RawMessage values that contain valid JSON
+// are embedded as is, see RawJSON.
+func (e *JSONEncoder) compileBytes(t reflect.Type) encoderFunc {
+	if !isBytes(t) {
+		return nil
+	}
+
+	var enc encoderFunc
+	switch e.BytesFormat {
+	case BytesNumbers:
+		enc = e.compileSlice(t)
+	case BytesString:
+		enc = func(b *Buffer, v reflect.Value) {
+			e.StringValue(b, string(v.Bytes()))
+		}
+	default:
+		enc = func(b *Buffer, v reflect.Value) {
+			b.WriteByte('"')
+			b.WriteString(bytesString(e.BytesFormat, v.Bytes()))
+			b.WriteByte('"')
+		}
+	}
+
+	if e.DenyByDefault && e.BytesFormat != BytesNumbers {
+		enc = denyLoose(enc, e.reportedMask(t, ReasonDenyByDefault, e.writeMask))
+	}
+
+	if t == rawMessageType {
+		enc = e.compileRawMessage(enc, "null")
+	}
+
+	return enc
+}
+
+// compileRawMessage returns an encoder function that embeds json.RawMessage values.
+// Empty values are written as the given null value, and values that don't contain valid JSON
+// are encoded with the invalid function.
+func (e *JSONEncoder) compileRawMessage(invalid encoderFunc, null string) encoderFunc {
+	return func(b *Buffer, v reflect.Value) {
+		raw := v.Bytes()
+		if len(raw) == 0 {
+			b.WriteString(null)
+
+			return
+		}
+
+		// The value is validated first, so nothing is written if it turns out to be invalid.
+		if !json.Valid(raw) {
+			invalid(b, v)
+
+			return
+		}
+
+		// The error is impossible, since the value is already validated.
+		_ = e.RawJSON(b, raw)
+	}
+}
+
+// compileBytes returns an encoder function for byte slices of the given type if they aren't written
+// as lists of numbers. Otherwise, it returns nil. json.RawMessage values that contain valid JSON
+// are embedded as compact JSON, see JSONEncoder.RawJSON.
+func (e *TextEncoder) compileBytes(t reflect.Type) encoderFunc {
+	if !isBytes(t) {
+		return nil
+	}
+
+	var enc encoderFunc
+	switch e.BytesFormat {
+	case BytesNumbers:
+		enc = e.compileSlice(t)
+	case BytesString:
+		enc = func(b *Buffer, v reflect.Value) {
+			if s := string(v.Bytes()); e.isLongString(s) {
+				e.writeTruncatedString(b, s)
+			} else {
+				e.String(b, s)
+			}
+		}
+	default:
+		enc = func(b *Buffer, v reflect.Value) {
+			b.WriteString(bytesString(e.BytesFormat, v.Bytes()))
+		}
+	}
+
+	if e.DenyByDefault && e.BytesFormat != BytesNumbers {
+		enc = denyLoose(enc, e.reportedMask(t, ReasonDenyByDefault, e.writeMask))
+	}
+
+	if t == rawMessageType {
+		enc = e.raw.compileRawMessage(enc, "nil")
+	}
+
+	return enc
+}
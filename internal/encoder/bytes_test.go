@@ -0,0 +1,138 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_BytesFormat(t *testing.T) {
+	type body []byte
+
+	type request struct {
+		Body    []byte          `censor:"display"`
+		Payload json.RawMessage `censor:"display"`
+		Secret  []byte
+	}
+
+	tests := map[string]struct {
+		cfg     Config
+		val     any
+		expJSON string
+		expText string
+	}{
+		"numbers": {
+			val:     []byte("hi"),
+			expJSON: `[104, 105]`,
+			expText: `[104, 105]`,
+		},
+		"base64": {
+			cfg:     Config{BytesFormat: BytesBase64},
+			val:     []byte("hello"),
+			expJSON: `"aGVsbG8="`,
+			expText: `aGVsbG8=`,
+		},
+		"hex": {
+			cfg:     Config{BytesFormat: BytesHex},
+			val:     body("hello"),
+			expJSON: `"68656c6c6f"`,
+			expText: `68656c6c6f`,
+		},
+		"string": {
+			cfg:     Config{BytesFormat: BytesString, ExcludePatterns: []string{`\d{4}`}},
+			val:     []byte(`card=4111 "ok"`),
+			expJSON: `"card=[CENSORED] \"ok\""`,
+			expText: `card=[CENSORED] "ok"`,
+		},
+		"string_max_length": {
+			cfg:     Config{BytesFormat: BytesString, MaxStringLength: 2},
+			val:     []byte("hello"),
+			expJSON: `"he...(+3 more)"`,
+			expText: `he...(+3 more)`,
+		},
+		"length_only": {
+			cfg:     Config{BytesFormat: BytesLength},
+			val:     []byte("hello"),
+			expJSON: `"(5 bytes)"`,
+			expText: `(5 bytes)`,
+		},
+		"nil": {
+			cfg:     Config{BytesFormat: BytesLength},
+			val:     []byte(nil),
+			expJSON: `"(0 bytes)"`,
+			expText: `(0 bytes)`,
+		},
+		"byte_array_is_not_affected": {
+			cfg:     Config{BytesFormat: BytesHex},
+			val:     [2]byte{1, 2},
+			expJSON: `[1, 2]`,
+			expText: `[1, 2]`,
+		},
+		"deny_by_default": {
+			cfg:     Config{BytesFormat: BytesString, DenyByDefault: true},
+			val:     []byte("hello"),
+			expJSON: `"[CENSORED]"`,
+			expText: `[CENSORED]`,
+		},
+		"raw_message": {
+			cfg:     Config{MaskMapKeys: []string{"password"}},
+			val:     json.RawMessage(`{"login": "ivan", "password": "hunter2"}`),
+			expJSON: `{"login":"ivan","password":"[CENSORED]"}`,
+			expText: `{"login":"ivan","password":"[CENSORED]"}`,
+		},
+		"invalid_raw_message": {
+			cfg:     Config{BytesFormat: BytesString},
+			val:     json.RawMessage(`{"login": `),
+			expJSON: `"{\"login\": "`,
+			expText: `{"login": `,
+		},
+		"invalid_raw_message_deny_by_default": {
+			cfg:     Config{BytesFormat: BytesString, DenyByDefault: true},
+			val:     json.RawMessage(`{"login": `),
+			expJSON: `"[CENSORED]"`,
+			expText: `[CENSORED]`,
+		},
+		"empty_raw_message": {
+			val:     json.RawMessage(nil),
+			expJSON: `null`,
+			expText: `nil`,
+		},
+		"struct": {
+			cfg:     Config{BytesFormat: BytesString},
+			val:     request{Body: []byte("body"), Payload: json.RawMessage(`[1, "a"]`), Secret: []byte("secret")},
+			expJSON: `{"Body": "body","Payload": [1,"a"],"Secret": "[CENSORED]"}`,
+			expText: `{Body: body, Payload: [1,"a"], Secret: [CENSORED]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.cfg.MaskValue = "[CENSORED]"
+
+			t.Run("json", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				NewJSONEncoder(tt.cfg).Encode(NewBuffer(&b), reflect.ValueOf(tt.val))
+
+				// THEN.
+				require.Equal(t, tt.expJSON, b.String())
+			})
+
+			t.Run("text", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				NewTextEncoder(tt.cfg).Encode(NewBuffer(&b), reflect.ValueOf(tt.val))
+
+				// THEN.
+				require.Equal(t, tt.expText, b.String())
+			})
+		})
+	}
+}
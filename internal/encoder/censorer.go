@@ -0,0 +1,42 @@
+package encoder
+
+import "reflect"
+
+// censorer mirrors censor.Censorer interface. It's implemented by types that control their own masked representation.
+type censorer interface {
+	CensorValue() any
+}
+
+var censorerType = reflect.TypeFor[censorer]()
+
+// censoredValue returns the value returned by the CensorValue method if the given value implements
+// the censorer interface. If the method has a pointer receiver, it's used only for addressable values.
+// Nil pointers and interfaces are ignored.
+//
+//nolint:exhaustive
+func censoredValue(v reflect.Value) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+	}
+
+	if !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+
+	if !v.Type().Implements(censorerType) {
+		if !v.CanAddr() || !reflect.PointerTo(v.Type()).Implements(censorerType) {
+			return reflect.Value{}, false
+		}
+		v = v.Addr()
+	}
+
+	c, ok := v.Interface().(censorer)
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	return reflect.ValueOf(c.CensorValue()), true
+}
@@ -0,0 +1,113 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type censoredCard struct {
+	Number string
+	Holder string `censor:"display"`
+}
+
+func (c censoredCard) CensorValue() any {
+	return struct {
+		Last4  string `censor:"display"`
+		Holder string `censor:"display"`
+	}{Last4: c.Number[len(c.Number)-4:], Holder: c.Holder}
+}
+
+type censoredUser struct {
+	Name     string `censor:"display"`
+	Password string `censor:"display"`
+}
+
+// CensorValue returns a value of the same type, so it must be encoded without calling CensorValue again.
+func (u *censoredUser) CensorValue() any {
+	return censoredUser{Name: u.Name}
+}
+
+type censoredString string
+
+func (s censoredString) CensorValue() any {
+	return "*" + string(s[len(s)-1:])
+}
+
+func Test_censoredValue(t *testing.T) {
+	t.Run("not a censorer", func(t *testing.T) {
+		_, ok := censoredValue(reflect.ValueOf("string"))
+		require.False(t, ok)
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		_, ok := censoredValue(reflect.ValueOf((*censoredUser)(nil)))
+		require.False(t, ok)
+	})
+
+	t.Run("pointer receiver with non-addressable value", func(t *testing.T) {
+		_, ok := censoredValue(reflect.ValueOf(censoredUser{}))
+		require.False(t, ok)
+	})
+
+	t.Run("pointer receiver with addressable value", func(t *testing.T) {
+		// GIVEN.
+		u := censoredUser{Name: "Ivan", Password: "secret"}
+
+		// WHEN.
+		got, ok := censoredValue(reflect.ValueOf(&u).Elem())
+
+		// THEN.
+		require.True(t, ok)
+		require.Equal(t, censoredUser{Name: "Ivan"}, got.Interface())
+	})
+}
+
+func TestEncoder_Censorer(t *testing.T) {
+	type payload struct {
+		Card   censoredCard     `censor:"display"`
+		User   *censoredUser    `censor:"display"`
+		Code   censoredString   `censor:"display"`
+		Codes  []censoredString `censor:"display"`
+		Hidden censoredCard
+	}
+
+	value := payload{
+		Card:   censoredCard{Number: "4111111111111234", Holder: "Ivan"},
+		User:   &censoredUser{Name: "Ivan", Password: "secret"},
+		Code:   "1234",
+		Codes:  []censoredString{"12", "34"},
+		Hidden: censoredCard{Number: "4111111111111234", Holder: "Ivan"},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		exp := `{"Card": {"Last4": "1234","Holder": "Ivan"},"User": {"Name": "Ivan","Password": ""},` +
+			`"Code": "*4","Codes": ["*2", "*4"],"Hidden": "[CENSORED]"}`
+		require.Equal(t, exp, b.String())
+	})
+
+	t.Run("text", func(t *testing.T) {
+		// GIVEN.
+		e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		exp := `{Card: {Last4: 1234, Holder: Ivan}, User: {Name: Ivan, Password: }, Code: *4, Codes: [*2, *4], Hidden: [CENSORED]}`
+		require.Equal(t, exp, b.String())
+	})
+}
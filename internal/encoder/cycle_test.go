@@ -0,0 +1,111 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type cycleNode struct {
+	Value  int        `censor:"display"`
+	Next   *cycleNode `censor:"display"`
+	Parent *cycleNode `censor:"display"`
+}
+
+func TestEncoder_Cycles(t *testing.T) {
+	// A list with a back reference to the head.
+	list := &cycleNode{Value: 1}
+	list.Next = &cycleNode{Value: 2, Next: list}
+
+	// A tree where the child refers to its parent.
+	parent := &cycleNode{Value: 1}
+	parent.Next = &cycleNode{Value: 2, Parent: parent}
+
+	// The same pointer is used twice, but there is no cycle.
+	shared := &cycleNode{Value: 3}
+	dag := []*cycleNode{shared, shared}
+
+	m := map[string]any{"key": "value"}
+	m["self"] = m
+
+	s := []any{1, nil}
+	s[1] = s
+
+	tests := map[string]struct {
+		value   any
+		expJSON string
+		expText string
+	}{
+		"linked list": {
+			value:   list,
+			expJSON: `{"Value": 1,"Next": {"Value": 2,"Next": "[CYCLE]","Parent": null},"Parent": null}`,
+			expText: `{Value: 1, Next: {Value: 2, Next: <cycle>, Parent: nil}, Parent: nil}`,
+		},
+		"parent pointer": {
+			value:   parent,
+			expJSON: `{"Value": 1,"Next": {"Value": 2,"Next": null,"Parent": "[CYCLE]"},"Parent": null}`,
+			expText: `{Value: 1, Next: {Value: 2, Next: nil, Parent: <cycle>}, Parent: nil}`,
+		},
+		"shared pointer": {
+			value:   dag,
+			expJSON: `[{"Value": 3,"Next": null,"Parent": null}, {"Value": 3,"Next": null,"Parent": null}]`,
+			expText: `[{Value: 3, Next: nil, Parent: nil}, {Value: 3, Next: nil, Parent: nil}]`,
+		},
+		"slice": {
+			value:   s,
+			expJSON: `[1, "[CYCLE]"]`,
+			expText: `[1, <cycle>]`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Run("json", func(t *testing.T) {
+				// GIVEN.
+				e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+				var b bytes.Buffer
+
+				// WHEN.
+				e.Encode(NewBuffer(&b), reflect.ValueOf(tt.value))
+
+				// THEN.
+				require.Equal(t, tt.expJSON, b.String())
+			})
+
+			t.Run("text", func(t *testing.T) {
+				// GIVEN.
+				e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+				var b bytes.Buffer
+
+				// WHEN.
+				e.Encode(NewBuffer(&b), reflect.ValueOf(tt.value))
+
+				// THEN.
+				require.Equal(t, tt.expText, b.String())
+			})
+		})
+	}
+
+	t.Run("map", func(t *testing.T) {
+		// GIVEN.
+		jsonEncoder := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		textEncoder := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+		var jb, tb bytes.Buffer
+
+		// WHEN.
+		jsonEncoder.Encode(NewBuffer(&jb), reflect.ValueOf(m))
+		textEncoder.Encode(NewBuffer(&tb), reflect.ValueOf(m))
+
+		// THEN.
+		require.Contains(t, []string{
+			`{"key":"value","self":"[CYCLE]"}`,
+			`{"self":"[CYCLE]","key":"value"}`,
+		}, jb.String())
+		require.Contains(t, []string{
+			`map[string]interface {}{key: value, self: <cycle>}`,
+			`map[string]interface {}{self: <cycle>, key: value}`,
+		}, tb.String())
+	})
+}
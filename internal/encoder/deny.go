@@ -0,0 +1,70 @@
+package encoder
+
+import "reflect"
+
+// show marks values encoded next as explicitly displayed and returns the previous state,
+// which must be restored with b.shown = prev once the values are encoded.
+func (b *Buffer) show() bool {
+	prev := b.shown
+	b.shown = true
+
+	return prev
+}
+
+// isLoose reports whether values of the given kind are masked in the deny-by-default mode
+// unless they are reached through a displayed struct field or an allowed map key.
+//
+//nolint:exhaustive
+func isLoose(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// denyLoose wraps the given encoder function of a scalar type, so loose values are written with the mask function.
+func denyLoose(next encoderFunc, mask func(b *Buffer)) encoderFunc {
+	return func(b *Buffer, v reflect.Value) {
+		if !b.shown {
+			mask(b)
+
+			return
+		}
+
+		next(b, v)
+	}
+}
+
+// maskMapValue returns the reason why the value of the given map key must be masked,
+// or an empty string if it must be displayed.
+// checkKeys and allowKeys report whether keys of the map type can be matched by the MaskMapKeys
+// and DisplayMapKeys patterns respectively. MaskMapKeys take precedence.
+func (e *baseEncoder) maskMapValue(key reflect.Value, checkKeys, allowKeys bool) MaskReason {
+	if checkKeys && e.maskMapKeys.matched(key, &e.secrets) {
+		return ReasonMapKey
+	}
+
+	if !e.DenyByDefault || allowKeys && e.displayMapKeys.matched(key, &e.secrets) {
+		return ""
+	}
+
+	return ReasonDenyByDefault
+}
+
+// maskMember is the same as maskMapValue, but for members of JSON objects, which always have string keys.
+func (e *baseEncoder) maskMember(key string) MaskReason {
+	if e.maskMapKeys != nil && e.maskMapKeys.matchedString(key, &e.secrets) {
+		return ReasonMapKey
+	}
+
+	if !e.DenyByDefault || e.displayMapKeys != nil && e.displayMapKeys.matchedString(key, &e.secrets) {
+		return ""
+	}
+
+	return ReasonDenyByDefault
+}
@@ -0,0 +1,121 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_DenyByDefault(t *testing.T) {
+	type user struct {
+		ID    int               `censor:"display"`
+		Name  string            `censor:"display"`
+		Tags  []string          `censor:"display"`
+		Meta  map[string]string `censor:"display"`
+		Email string
+	}
+
+	cfg := Config{
+		MaskValue:      "[CENSORED]",
+		DenyByDefault:  true,
+		DisplayMapKeys: []string{"^id$", "^status$", "^user$"},
+		MaskMapKeys:    []string{"^status$"},
+	}
+
+	tests := map[string]struct {
+		val     any
+		expJSON string
+		expText string
+	}{
+		"string": {
+			val:     "secret",
+			expJSON: `"[CENSORED]"`,
+			expText: `[CENSORED]`,
+		},
+		"number": {
+			val:     42,
+			expJSON: `"[CENSORED]"`,
+			expText: `[CENSORED]`,
+		},
+		"pointer_to_bool": {
+			val:     func() *bool { v := true; return &v }(),
+			expJSON: `"[CENSORED]"`,
+			expText: `[CENSORED]`,
+		},
+		"slice": {
+			val:     []string{"a", "b"},
+			expJSON: `["[CENSORED]", "[CENSORED]"]`,
+			expText: `[[CENSORED], [CENSORED]]`,
+		},
+		"allowed_key": {
+			val:     map[string]int{"id": 1},
+			expJSON: `{"id":1}`,
+			expText: `map[string]int{id: 1}`,
+		},
+		"not_allowed_key": {
+			val:     map[string]int{"balance": 1},
+			expJSON: `{"balance":"[CENSORED]"}`,
+			expText: `map[string]int{balance: [CENSORED]}`,
+		},
+		"mask_map_keys_take_precedence": {
+			val:     map[string]string{"status": "active"},
+			expJSON: `{"status":"[CENSORED]"}`,
+			expText: `map[string]string{status: [CENSORED]}`,
+		},
+		"non_string_keys": {
+			val:     map[int]string{1: "one"},
+			expJSON: `{1:"[CENSORED]"}`,
+			expText: `map[int]string{1: [CENSORED]}`,
+		},
+		"struct": {
+			val:     user{ID: 1, Name: "Ivan", Tags: []string{"admin"}, Meta: map[string]string{"id": "7"}, Email: "ivan@example.com"},
+			expJSON: `{"ID": 1,"Name": "Ivan","Tags": ["admin"],"Meta": {"id":"7"},"Email": "[CENSORED]"}`,
+			expText: `{ID: 1, Name: Ivan, Tags: [admin], Meta: map[string]string{id: 7}, Email: [CENSORED]}`,
+		},
+		"struct_in_allowed_key": {
+			val:     map[string]user{"user": {ID: 1, Meta: map[string]string{"role": "admin"}}},
+			expJSON: `{"user":{"ID": 1,"Name": "","Tags": [],"Meta": {"role":"[CENSORED]"},"Email": "[CENSORED]"}}`,
+			expText: `map[string]encoder.user{user: {ID: 1, Name: , Tags: [], Meta: map[string]string{role: [CENSORED]}, Email: [CENSORED]}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Run("json", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				NewJSONEncoder(cfg).Encode(NewBuffer(&b), reflect.ValueOf(tt.val))
+
+				// THEN.
+				require.Equal(t, tt.expJSON, b.String())
+			})
+
+			t.Run("text", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				NewTextEncoder(cfg).Encode(NewBuffer(&b), reflect.ValueOf(tt.val))
+
+				// THEN.
+				require.Equal(t, tt.expText, b.String())
+			})
+		})
+	}
+}
+
+func TestEncoder_DenyByDefault_Disabled(t *testing.T) {
+	// GIVEN.
+	var b bytes.Buffer
+	e := NewJSONEncoder(Config{MaskValue: "[CENSORED]", DisplayMapKeys: []string{"^id$"}})
+
+	// WHEN.
+	e.Encode(NewBuffer(&b), reflect.ValueOf(map[string]string{"name": "Ivan"}))
+
+	// THEN.
+	require.Equal(t, `{"name":"Ivan"}`, b.String())
+}
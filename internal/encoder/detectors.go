@@ -0,0 +1,405 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// detector finds sensitive values of a specific kind in strings.
+// Candidates are found with a regexp pattern and then validated, so similar values (e.g. order IDs that look
+// like card numbers) are not masked.
+type detector struct {
+	// name identifies a named pattern or a built-in detector.
+	name string
+	// reason is reported for the matches of the detector, see Config.OnMask.
+	reason  MaskReason
+	pattern *regexp.Regexp
+	// group is the index of the pattern group that is masked. Zero means the whole match.
+	group int
+	// bounded requires a match not to be a part of a longer token, e.g. an IP address within a version string.
+	bounded bool
+	// check validates a candidate and returns the range of its valid part, or an empty range if it's not valid.
+	// A nil function means that every candidate is valid.
+	check func(candidate string) (start, end int)
+	// replace is a template of the replacement that may refer to the pattern groups, e.g. "$1***@$2".
+	// An empty template means the default replacement, see baseEncoder.replacement.
+	replace string
+}
+
+// match is a range of a string that must be replaced.
+type match struct {
+	start, end int
+	// replaced reports whether the match has its own replacement. Otherwise, the default one is used.
+	replaced    bool
+	replacement string
+	// secret reports whether the match contains a registered secret.
+	secret bool
+	// by is the detector that found the match. It's nil for matches of ExcludePatterns and secrets.
+	by *detector
+}
+
+// detectors contains the supported detectors by name.
+var detectors = map[string]*detector{
+	"credit-card": {
+		name:    "credit-card",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,}\b`),
+		check:   checkCard,
+	},
+	"iban": {
+		name:    "iban",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		check:   checkIBAN,
+	},
+	"email": {
+		name:    "email",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}\b`),
+	},
+	"jwt": {
+		name:    "jwt",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]*\.eyJ[A-Za-z0-9_\-]*\.[A-Za-z0-9_\-]+`),
+		check:   checkJWT,
+	},
+	"aws-access-key": {
+		name:    "aws-access-key",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`),
+	},
+	"bearer-token": {
+		name:    "bearer-token",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+		group:   1,
+	},
+	"ipv4": {
+		name:    "ipv4",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`),
+		bounded: true,
+		check:   checkIP(false),
+	},
+	"ipv6": {
+		name:    "ipv6",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`(?:[0-9A-Fa-f]{0,4}:){2,7}(?:\d{1,3}(?:\.\d{1,3}){3}|[0-9A-Fa-f]{1,4})?`),
+		bounded: true,
+		check:   checkIP(true),
+	},
+	"phone-e164": {
+		name:    "phone-e164",
+		reason:  ReasonDetector,
+		pattern: regexp.MustCompile(`\+[1-9]\d{6,14}\b`),
+	},
+}
+
+// ValidDetector reports whether a detector with the given name exists.
+func ValidDetector(name string) bool {
+	_, ok := detectors[name]
+
+	return ok
+}
+
+// DetectorNames returns the sorted names of all the supported detectors.
+func DetectorNames() []string {
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// newDetectors returns the named patterns, the built-in detectors with the configured names
+// and the entropy detector if it's configured. Unknown names are ignored.
+func newDetectors(c Config) []*detector {
+	ds := newNamedPatterns(c.NamedPatterns)
+	for _, name := range c.Detectors {
+		if d, ok := detectors[name]; ok && !slices.Contains(ds, d) {
+			ds = append(ds, d)
+		}
+	}
+
+	if d := newEntropyDetector(c.Entropy); d != nil {
+		ds = append(ds, d)
+	}
+
+	return ds
+}
+
+// appendMatches appends the ranges of valid values found in s to matches.
+func (d *detector) appendMatches(matches []match, s string) []match {
+	for _, m := range d.pattern.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[2*d.group], m[2*d.group+1]
+		if start < 0 {
+			continue
+		}
+
+		if d.check == nil {
+			matches = d.appendMatch(matches, s, m, start, end)
+
+			continue
+		}
+
+		// A candidate may contain several valid values, e.g. two card numbers separated by a space,
+		// so the rest of it is checked again after a valid one.
+		for start < end {
+			from, to := d.check(s[start:end])
+			if from == to {
+				break
+			}
+			matches = d.appendMatch(matches, s, m, start+from, start+to)
+			start += to
+		}
+	}
+
+	return matches
+}
+
+// appendMatch appends the given range of s found within the pattern match m to matches if it's valid.
+func (d *detector) appendMatch(matches []match, s string, m []int, start, end int) []match {
+	if d.bounded && !isBoundary(s, start, end) {
+		return matches
+	}
+
+	found := match{start: start, end: end, by: d}
+	if d.replace != "" {
+		found.replaced = true
+		found.replacement = string(d.pattern.ExpandString(nil, d.replace, s, m))
+	}
+
+	return append(matches, found)
+}
+
+// isBoundary reports whether the range of s is not adjacent to letters, digits or '_', including the ones
+// separated by a single period, e.g. "v1.2.3.4" or "1.2.3.4.5". A period at the end of a sentence is allowed.
+func isBoundary(s string, start, end int) bool {
+	before := start == 0 || (!isWordByte(s[start-1]) &&
+		!(s[start-1] == '.' && start > 1 && isWordByte(s[start-2])))
+	after := end == len(s) || (!isWordByte(s[end]) &&
+		!(s[end] == '.' && end+1 < len(s) && isWordByte(s[end+1])))
+
+	return before && after
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// checkCard finds a card number in a run of digit groups separated by spaces or dashes. The run may contain
+// other numbers, e.g. "qty 1 4111 1111 1111 1111", so ranges of whole groups with 13 to 19 digits
+// are validated with the Luhn algorithm. It returns the longest valid range starting at the earliest possible group.
+func checkCard(candidate string) (start, end int) {
+	// groups contains the start and the end of each digit group.
+	var groups [][2]int
+	for i := 0; i < len(candidate); i++ {
+		if c := candidate[i]; c == ' ' || c == '-' {
+			continue
+		}
+
+		j := i
+		for j < len(candidate) && candidate[j] != ' ' && candidate[j] != '-' {
+			j++
+		}
+		groups = append(groups, [2]int{i, j})
+		i = j
+	}
+
+	for i := range groups {
+		var digits int
+		for j := i; j < len(groups); j++ {
+			digits += groups[j][1] - groups[j][0]
+			if digits > maxCardDigits {
+				break
+			}
+
+			if digits >= minCardDigits && luhn(candidate[groups[i][0]:groups[j][1]]) {
+				start, end = groups[i][0], groups[j][1]
+			}
+		}
+
+		if start != end {
+			return start, end
+		}
+	}
+
+	return 0, 0
+}
+
+// minCardDigits and maxCardDigits limit the number of digits of a card number.
+const (
+	minCardDigits = 13
+	maxCardDigits = 19
+)
+
+// luhn validates a number with the Luhn algorithm. Spaces and dashes are ignored.
+func luhn(number string) bool {
+	var sum, digits int
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+
+	return sum%10 == 0
+}
+
+// ibanLengths contains the lengths of IBANs by country code.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BR": 29,
+	"BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DK": 18, "DO": 28, "EE": 20, "EG": 29,
+	"ES": 24, "FI": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28,
+	"HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24, "ME": 22, "MK": 19,
+	"MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29,
+	"RO": 24, "RS": 22, "SA": 24, "SC": 31, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// checkIBAN validates an IBAN with the mod-97 algorithm. The candidate may contain spaces and be followed
+// by other characters, so only as many characters as the country requires are checked.
+func checkIBAN(candidate string) (start, end int) {
+	if len(candidate) < 2 {
+		return 0, 0
+	}
+
+	length, ok := ibanLengths[candidate[:2]]
+	if !ok {
+		return 0, 0
+	}
+
+	var compact strings.Builder
+	for end < len(candidate) && compact.Len() < length {
+		if c := candidate[end]; c != ' ' {
+			compact.WriteByte(c)
+		}
+		end++
+	}
+
+	if compact.Len() < length || (end < len(candidate) && candidate[end] != ' ') {
+		return 0, 0
+	}
+
+	// The country code and check digits are moved to the end, and letters are replaced with numbers
+	// (A = 10, ..., Z = 35). The remainder is computed digit by digit, so the number never overflows.
+	iban := compact.String()
+	var remainder int
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+
+	if remainder != 1 {
+		return 0, 0
+	}
+
+	return 0, end
+}
+
+// checkJWT validates that the header of a JWT is a JSON object.
+func checkJWT(candidate string) (start, end int) {
+	header, _, _ := strings.Cut(candidate, ".")
+	decoded, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil || !json.Valid(decoded) {
+		return 0, 0
+	}
+
+	return 0, len(candidate)
+}
+
+// checkIP returns a function that validates IPv4 or IPv6 addresses. The unspecified IPv6 address is not valid,
+// as "::" is more likely a scope operator, e.g. "std::vector", than an address.
+func checkIP(v6 bool) func(candidate string) (start, end int) {
+	return func(candidate string) (start, end int) {
+		addr, err := netip.ParseAddr(candidate)
+		if err != nil || addr.Is6() != v6 || v6 && addr.IsUnspecified() {
+			return 0, 0
+		}
+
+		return 0, len(candidate)
+	}
+}
+
+// findMatches returns sorted and non-overlapping ranges of s that are matched by the exclude patterns,
+// the detectors or the given secrets matcher, which may be nil. Matches are reported before they are joined,
+// see Config.OnMask.
+func (e *baseEncoder) findMatches(b *Buffer, s string, secrets *secretMatcher) []match {
+	var matches []match
+	if e.ExcludePatternsCompiled != nil {
+		for _, m := range e.ExcludePatternsCompiled.FindAllStringIndex(s, -1) {
+			matches = append(matches, match{start: m[0], end: m[1]})
+		}
+	}
+
+	if len(e.detectors) == 0 && secrets == nil {
+		if e.audited {
+			e.reportMatches(b, matches)
+		}
+
+		return matches
+	}
+
+	for _, d := range e.detectors {
+		matches = d.appendMatches(matches, s)
+	}
+
+	if secrets != nil {
+		matches = secrets.appendMatches(matches, s)
+	}
+
+	if e.audited {
+		e.reportMatches(b, matches)
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+
+		return b.end - a.end
+	})
+
+	// Overlapping ranges are joined, so no part of a sensitive value stays visible. Own replacements
+	// of joined ranges may expose parts of other matches, so the default replacement is used instead.
+	merged := matches[:0]
+	for _, m := range matches {
+		if last := len(merged) - 1; last >= 0 && m.start < merged[last].end {
+			merged[last] = match{
+				start:  merged[last].start,
+				end:    max(merged[last].end, m.end),
+				secret: merged[last].secret || m.secret,
+			}
+
+			continue
+		}
+		merged = append(merged, m)
+	}
+
+	return merged
+}
+
+// censorsStrings reports whether strings may contain segments that must be replaced.
+func (e *baseEncoder) censorsStrings() bool {
+	return e.ExcludePatternsCompiled != nil || len(e.detectors) != 0 || e.secrets.matcher.Load() != nil
+}
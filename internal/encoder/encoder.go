@@ -1,28 +1,35 @@
 package encoder
 
 import (
-	"bytes"
 	"reflect"
 	"regexp"
-
-	"github.com/vpakhuchyi/censor/internal/cache"
 )
 
 const (
 	defaultCensorFieldTag = "censor"
 	unsupportedTypeTmpl   = "unsupported type="
 	display               = "display"
+	// jsonCycleMarker is written instead of a value that refers to itself in the JSON format.
+	jsonCycleMarker = `"[CYCLE]"`
+	// textCycleMarker is written instead of a value that refers to itself in the TEXT format.
+	textCycleMarker = "<cycle>"
+	// jsonTruncationMarker is written instead of a value that exceeds the configured limits in the JSON format.
+	jsonTruncationMarker = `"` + truncationMarker + `"`
 )
 
 // Encoder is an interface that describes the behavior of the encoder.
 type Encoder interface {
-	Struct(b *bytes.Buffer, rv reflect.Value)
-	Ptr(b *bytes.Buffer, rv reflect.Value)
-	Slice(b *bytes.Buffer, rv reflect.Value)
-	Map(b *bytes.Buffer, rv reflect.Value)
-	Interface(b *bytes.Buffer, rv reflect.Value)
-	String(b *bytes.Buffer, s string)
-	Encode(b *bytes.Buffer, f reflect.Value)
+	Struct(b *Buffer, rv reflect.Value)
+	Ptr(b *Buffer, rv reflect.Value)
+	Slice(b *Buffer, rv reflect.Value)
+	Map(b *Buffer, rv reflect.Value)
+	Interface(b *Buffer, rv reflect.Value)
+	String(b *Buffer, s string)
+	Encode(b *Buffer, f reflect.Value)
+	RegisterType(t reflect.Type, fn TypeFunc)
+	RegisteredTypes() map[reflect.Type]TypeFunc
+	RegisterSecrets(values ...string)
+	RegisteredSecrets() []string
 }
 
 // Config describes censor Encoder configuration.
@@ -46,6 +53,58 @@ type Config struct {
 	// UseJSONTagName sets whether to use the `json` tag to get the name of the struct field.
 	// If no `json` tag is present, the name of the struct field is used.
 	UseJSONTagName bool `yaml:"use-json-tag-name"`
+	// HashKey is a secret key that is used to compute HMAC-SHA256 digests of hashed values.
+	// If it's empty, values that must be hashed are masked instead.
+	HashKey string `yaml:"hash-key"`
+	// HashExcludePatterns sets whether strings matched by ExcludePatterns are replaced
+	// with a digest instead of MaskValue.
+	HashExcludePatterns bool `yaml:"hash-exclude-patterns"`
+	// DefaultFieldAction is used for struct fields without a censor tag.
+	// The default value is ActionMask.
+	DefaultFieldAction Action `yaml:"default-field-action"`
+	// MaskMapKeys contains regexp patterns of map keys whose values must be masked.
+	MaskMapKeys []string `yaml:"mask-map-keys"`
+	// DenyByDefault makes map values and loose scalars masked unless they are explicitly allowed.
+	DenyByDefault bool `yaml:"deny-by-default"`
+	// DisplayMapKeys contains regexp patterns of map keys whose values are displayed in the deny-by-default mode.
+	DisplayMapKeys []string `yaml:"display-map-keys"`
+	// BytesFormat sets how byte slices are written. The default value is BytesNumbers.
+	BytesFormat BytesFormat `yaml:"bytes-format"`
+	// Detectors contains names of the built-in detectors of sensitive values, e.g. "credit-card" or "email".
+	Detectors []string `yaml:"detectors"`
+	// NamedPatterns contains regexp patterns that are applied along with ExcludePatterns,
+	// but may have their own replacements.
+	NamedPatterns []NamedPattern `yaml:"named-patterns"`
+	// CacheMode sets how encoded strings are used as keys of the internal caches. The default value is CachePlain.
+	CacheMode CacheMode `yaml:"cache-mode"`
+	// CacheSize is the maximum number of entries of each internal cache of strings.
+	// Zero means cache.DefaultMaxCacheSize.
+	CacheSize int `yaml:"cache-size"`
+	// Entropy configures the detector of high-entropy tokens. It's disabled if nil.
+	Entropy *EntropyConfig `yaml:"entropy"`
+	// FieldRules contains censor tag values for struct fields by dotted paths like "github.com/acme/api.User.Email"
+	// or "*.Password". They take precedence over struct tags.
+	FieldRules map[string]string `yaml:"field-rules"`
+	// MaxDepth limits the number of nested structs, maps, slices and arrays.
+	// Deeper values are replaced with a truncation marker. Zero means no limit.
+	MaxDepth int `yaml:"max-depth"`
+	// MaxSliceLength limits the number of written slice and array elements. Zero means no limit.
+	MaxSliceLength int `yaml:"max-slice-length"`
+	// MaxMapEntries limits the number of written map entries. Zero means no limit.
+	MaxMapEntries int `yaml:"max-map-entries"`
+	// MaxStringLength limits the number of written characters of string values. Map keys are never truncated.
+	// Zero means no limit.
+	MaxStringLength int `yaml:"max-string-length"`
+	// MaxOutputBytes is a soft limit of the output size. Once it's reached, the remaining elements
+	// of collections and structs are replaced with a truncation marker. Zero means no limit.
+	MaxOutputBytes int `yaml:"max-output-bytes"`
+	// Inspect makes the encoder report all values, including the displayed ones, to the inspection of the Buffer
+	// instead of the OnMask hook, see Inspect.
+	Inspect bool `yaml:"-"`
+	// OnMask is called for every value that is masked, hashed, partially masked or omitted, e.g. to audit
+	// the configuration or to collect metrics. It's called synchronously, so it must be fast and safe
+	// for concurrent use. With the hook, paths of values are tracked, and censored strings are not cached.
+	OnMask func(MaskEvent) `yaml:"-"`
 }
 
 type baseEncoder struct {
@@ -62,26 +121,60 @@ type baseEncoder struct {
 	// UseJSONTagName sets whether to use the `json` tag to get the name of the struct field.
 	// If no `json` tag is present, the name of the struct field is used.
 	UseJSONTagName bool
-
-	// structFieldsCache is used to cache struct fields, so we don't need to use reflection every time.
-	// Note: fields of anonymous structs are not cached due to the absence of a name.
-	structFieldsCache *cache.TypeCache[[]Field]
+	// HashExcludePatterns sets whether strings matched by ExcludePatterns are replaced
+	// with a digest instead of MaskValue.
+	HashExcludePatterns bool
+	// DefaultFieldAction is used for struct fields without a censor tag.
+	DefaultFieldAction Action
+	// DenyByDefault sets whether map values and scalars that are not reached through a displayed struct field
+	// are masked unless their map keys are matched by DisplayMapKeys.
+	DenyByDefault bool
+	// BytesFormat sets how byte slices are written.
+	BytesFormat BytesFormat
+	// MaxDepth limits the number of nested structs, maps, slices and arrays.
+	MaxDepth int
+	// MaxSliceLength limits the number of written slice and array elements.
+	MaxSliceLength int
+	// MaxMapEntries limits the number of written map entries.
+	MaxMapEntries int
+	// MaxStringLength limits the number of written characters of string values.
+	MaxStringLength int
+	// MaxOutputBytes is a soft limit of the output size.
+	MaxOutputBytes int
+
+	// detectors find sensitive values in strings along with ExcludePatterns.
+	detectors []*detector
+	// fieldRules contains actions configured for struct fields by dotted paths.
+	fieldRules fieldRules
+	// maskMapKeys matches map keys whose values must be masked. It's nil if no patterns are configured.
+	maskMapKeys *mapKeys
+	// displayMapKeys matches map keys whose values are displayed in the deny-by-default mode.
+	displayMapKeys *mapKeys
+	// types contains functions registered for specific types with RegisterType.
+	types typeRegistry
+	// secrets contains literal values registered with RegisterSecrets.
+	secrets secretRegistry
+	// onMask is called for every masked value. It's nil if no hook is configured.
+	onMask func(MaskEvent)
+	// inspect reports whether the encoder is created in the inspection mode, see Config.Inspect.
+	inspect bool
+	// audited reports whether values are reported, either to the hook or to the inspection.
+	audited bool
+	// hasher is used to compute digests of hashed values. It's nil if no hash key is configured.
+	hasher *hasher
+	// plans contains encoder functions compiled for specific types, so we don't need to inspect types every time.
+	plans planCache
 	// escapedStringsCache is used to cache escaped strings, to improve performance.
-	escapedStringsCache *cache.Cache[string]
+	escapedStringsCache *stringCache[string]
 	// regexpCache is used to cache compiled regexp patterns, to improve performance.
-	regexpCache *cache.Cache[string]
+	regexpCache *stringCache[string]
 }
 
-// Field is a struct that contains information about a struct field.
-type Field struct {
-	Name     string
-	IsMasked bool
-}
-
-// WriteString processes the input string by masking any substrings that match the configured exclusion patterns.
+// WriteString processes the input string by masking any substrings that match the configured exclusion patterns
+// or detectors.
 // It replaces matched segments with a predefined mask value and writes the result directly to the buffer.
-func (e *baseEncoder) WriteString(b *bytes.Buffer, s string) {
-	if len(e.ExcludePatterns) == 0 || e.ExcludePatternsCompiled == nil {
+func (e *baseEncoder) WriteString(b *Buffer, s string) {
+	if !e.censorsStrings() {
 		b.WriteString(s)
 
 		return
@@ -94,10 +187,11 @@ func (e *baseEncoder) WriteString(b *bytes.Buffer, s string) {
 		return
 	}
 
-	matches := e.ExcludePatternsCompiled.FindAllStringIndex(s, -1)
+	secrets := e.secrets.matcher.Load()
+	matches := e.findMatches(b, s, secrets)
 	if len(matches) == 0 {
 		b.WriteString(s)
-		e.regexpCache.Set(s, s)
+		e.cacheString(e.regexpCache, s, s, secrets)
 
 		return
 	}
@@ -105,13 +199,43 @@ func (e *baseEncoder) WriteString(b *bytes.Buffer, s string) {
 	startLen := b.Len()
 	lastIndex := 0
 	for _, m := range matches {
-		start, end := m[0], m[1]
-		b.WriteString(s[lastIndex:start])
-		b.WriteString(e.MaskValue)
-		lastIndex = end
+		b.WriteString(s[lastIndex:m.start])
+		b.WriteString(e.replacement(s, m))
+		lastIndex = m.end
 	}
 	b.WriteString(s[lastIndex:])
 
-	result := b.String()[startLen:]
-	e.regexpCache.Set(s, result)
+	// Strings that contain secrets are not cached, so the secrets are not kept in memory as cache keys.
+	if !containsSecret(matches) {
+		e.cacheString(e.regexpCache, s, b.String()[startLen:], secrets)
+	}
+}
+
+// replacement returns a value that replaces the given match of s. Matches of named patterns may have
+// their own replacements, others are replaced with a digest or the mask value.
+func (e *baseEncoder) replacement(s string, m match) string {
+	if m.replaced {
+		return m.replacement
+	}
+
+	if e.HashExcludePatterns && e.hasher != nil {
+		return e.hasher.Sum(s[m.start:m.end])
+	}
+
+	return e.MaskValue
+}
+
+// hashedValue returns a digest of the given value. Only strings and numbers can be hashed.
+// If the value can't be hashed or no hash key is configured, ok is false and the value must be masked.
+func (e *baseEncoder) hashedValue(v reflect.Value) (string, bool) {
+	if e.hasher == nil {
+		return "", false
+	}
+
+	s, ok := scalarString(v)
+	if !ok {
+		return "", false
+	}
+
+	return e.hasher.Sum(s), true
 }
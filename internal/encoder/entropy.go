@@ -0,0 +1,79 @@
+package encoder
+
+import (
+	"math"
+	"regexp"
+)
+
+// EntropyConfig describes the detector of high-entropy tokens, e.g. API keys, random passwords or session IDs.
+type EntropyConfig struct {
+	// MinLength is the minimum length of a token in bytes.
+	MinLength int `yaml:"min-length"`
+	// MinEntropy is the minimum Shannon entropy of a token in bits per character.
+	MinEntropy float64 `yaml:"min-entropy"`
+	// MinCharClasses is the minimum number of character classes (lowercase letters, uppercase letters
+	// and digits) a token must contain.
+	MinCharClasses int `yaml:"min-char-classes"`
+}
+
+// entropyTokenPattern matches tokens that are checked by the entropy detector. Tokens consist of characters
+// used in base64 and URL-safe keys followed by optional padding, so other characters (spaces, punctuation,
+// "key=value" separators, etc.) split strings into tokens.
+var entropyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_\-]+=*`)
+
+// newEntropyDetector returns a detector of high-entropy tokens. It returns nil if c is nil.
+func newEntropyDetector(c *EntropyConfig) *detector {
+	if c == nil {
+		return nil
+	}
+
+	return &detector{
+		name:    entropyDetectorName,
+		reason:  ReasonDetector,
+		pattern: entropyTokenPattern,
+		check: func(candidate string) (start, end int) {
+			if len(candidate) < c.MinLength || charClasses(candidate) < c.MinCharClasses ||
+				shannonEntropy(candidate) < c.MinEntropy {
+				return 0, 0
+			}
+
+			return 0, len(candidate)
+		},
+	}
+}
+
+// shannonEntropy returns the Shannon entropy of the given string in bits per byte.
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		if n != 0 {
+			p := float64(n) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+
+	return entropy
+}
+
+// charClasses returns the number of character classes (lowercase letters, uppercase letters and digits)
+// used in the given string.
+func charClasses(s string) int {
+	var lower, upper, digit int
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z':
+			lower = 1
+		case c >= 'A' && c <= 'Z':
+			upper = 1
+		case c >= '0' && c <= '9':
+			digit = 1
+		}
+	}
+
+	return lower + upper + digit
+}
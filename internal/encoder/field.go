@@ -0,0 +1,209 @@
+package encoder
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maskTag         = "mask"
+	maskTagPrefix   = "mask="
+	maskFirstPrefix = "first"
+	maskLastPrefix  = "last"
+	maskEmail       = "email"
+	hashTag         = "hash"
+	omitTag         = "omit"
+)
+
+// Action describes how a struct field value is written to the output.
+type Action uint8
+
+const (
+	// ActionMask replaces the whole value with the mask value.
+	// It's the default action for all struct fields.
+	ActionMask Action = iota
+	// ActionDisplay writes the value as is.
+	ActionDisplay
+	// ActionMaskFirst keeps the first Field.Keep characters of the value visible and masks the rest.
+	ActionMaskFirst
+	// ActionMaskLast keeps the last Field.Keep characters of the value visible and masks the rest.
+	ActionMaskLast
+	// ActionMaskEmail keeps the domain part of an email address visible and masks the local part.
+	ActionMaskEmail
+	// ActionHash replaces the value with a truncated keyed HMAC-SHA256 digest.
+	ActionHash
+	// ActionOmit drops the field from the output entirely.
+	ActionOmit
+)
+
+// Field is a struct that contains information about a struct field.
+type Field struct {
+	Name   string
+	Action Action
+	// Keep is the number of characters that stay visible when ActionMaskFirst or ActionMaskLast is used.
+	Keep int
+}
+
+// parseCensorTag returns an Action and the number of visible characters described by the given censor tag value.
+// Supported values are "display", "hash", "omit", "mask", "mask=firstN", "mask=lastN" and "mask=email".
+// Any unknown or malformed value results in ActionMask, so a typo never exposes the value.
+func parseCensorTag(tag string) (Action, int) {
+	action, keep, _ := parseTag(tag)
+
+	return action, keep
+}
+
+// ValidTag reports whether the given censor tag value is supported.
+func ValidTag(tag string) bool {
+	_, _, ok := parseTag(tag)
+
+	return ok
+}
+
+// parseTag is the same as parseCensorTag, but it also reports whether the tag value is supported.
+func parseTag(tag string) (Action, int, bool) {
+	switch tag {
+	case display:
+		return ActionDisplay, 0, true
+	case hashTag:
+		return ActionHash, 0, true
+	case omitTag:
+		return ActionOmit, 0, true
+	case maskTag:
+		return ActionMask, 0, true
+	}
+
+	strategy, ok := strings.CutPrefix(tag, maskTagPrefix)
+	if !ok {
+		return ActionMask, 0, false
+	}
+
+	if strategy == maskEmail {
+		return ActionMaskEmail, 0, true
+	}
+
+	if n, ok := parseKeep(strategy, maskFirstPrefix); ok {
+		return ActionMaskFirst, n, true
+	}
+
+	if n, ok := parseKeep(strategy, maskLastPrefix); ok {
+		return ActionMaskLast, n, true
+	}
+
+	return ActionMask, 0, false
+}
+
+// FieldAction returns an Action and the number of visible characters for a field of the struct type
+// with the given name (see TypeName) and tag value. Field rules take precedence over the tag.
+// Fields without a censor tag get the configured default action.
+func (e *baseEncoder) FieldAction(typeName, field, tag string) (Action, int) {
+	if rule, ok := e.fieldRules.get(typeName, field); ok {
+		return rule.action, rule.keep
+	}
+
+	if tag == "" {
+		return e.DefaultFieldAction, 0
+	}
+
+	return parseCensorTag(tag)
+}
+
+// parseKeep parses a strategy like "last4" and returns the number of visible characters.
+func parseKeep(strategy, prefix string) (int, bool) {
+	s, ok := strings.CutPrefix(strategy, prefix)
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// partialValue returns the part of the given value that stays visible after applying one of the partial
+// masking actions. The second return value reports whether the mask goes before the visible part.
+// If the value can't be partially masked (unsupported kind, nil, too short, etc.), ok is false and
+// the value must be masked entirely.
+func partialValue(f Field, v reflect.Value) (visible string, maskFirst, ok bool) {
+	s, ok := scalarString(v)
+	if !ok {
+		return "", false, false
+	}
+
+	return partialString(f, s)
+}
+
+// partialString is the same as partialValue, but for strings.
+func partialString(f Field, s string) (visible string, maskFirst, ok bool) {
+	switch f.Action {
+	case ActionMaskFirst:
+		if utf8.RuneCountInString(s) <= f.Keep {
+			return "", false, false
+		}
+
+		return s[:runeOffset(s, f.Keep)], false, true
+	case ActionMaskLast:
+		n := utf8.RuneCountInString(s)
+		if n <= f.Keep {
+			return "", false, false
+		}
+
+		return s[runeOffset(s, n-f.Keep):], true, true
+	case ActionMaskEmail:
+		at := strings.LastIndexByte(s, '@')
+		if at <= 0 || at == len(s)-1 {
+			return "", false, false
+		}
+
+		return s[at:], true, true
+	default:
+		return "", false, false
+	}
+}
+
+// scalarString returns a string representation of strings and numbers.
+// Pointers and interfaces are dereferenced. Any other kind of value is not supported.
+//
+//nolint:exhaustive
+func scalarString(v reflect.Value) (string, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32:
+		return decimal.NewFromFloat32(float32(v.Float())).String(), true
+	case reflect.Float64:
+		return decimal.NewFromFloat(v.Float()).String(), true
+	default:
+		return "", false
+	}
+}
+
+// runeOffset returns the byte offset of the n-th rune in s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+
+	return len(s)
+}
@@ -0,0 +1,145 @@
+package encoder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseCensorTag(t *testing.T) {
+	tests := map[string]struct {
+		tag        string
+		expAction  Action
+		expVisible int
+		expValid   bool
+	}{
+		"empty":              {tag: "", expAction: ActionMask},
+		"display":            {tag: "display", expAction: ActionDisplay, expValid: true},
+		"mask":               {tag: "mask", expAction: ActionMask, expValid: true},
+		"mask_first":         {tag: "mask=first2", expAction: ActionMaskFirst, expVisible: 2, expValid: true},
+		"mask_last":          {tag: "mask=last4", expAction: ActionMaskLast, expVisible: 4, expValid: true},
+		"mask_email":         {tag: "mask=email", expAction: ActionMaskEmail, expValid: true},
+		"mask_last_zero":     {tag: "mask=last0", expAction: ActionMask},
+		"mask_last_negative": {tag: "mask=last-1", expAction: ActionMask},
+		"mask_last_no_num":   {tag: "mask=last", expAction: ActionMask},
+		"mask_unknown":       {tag: "mask=middle3", expAction: ActionMask},
+		"hash":               {tag: "hash", expAction: ActionHash, expValid: true},
+		"omit":               {tag: "omit", expAction: ActionOmit, expValid: true},
+		"unknown":            {tag: "show", expAction: ActionMask},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN.
+			action, keep := parseCensorTag(tt.tag)
+
+			// THEN.
+			require.Equal(t, tt.expAction, action)
+			require.Equal(t, tt.expVisible, keep)
+			require.Equal(t, tt.expValid, ValidTag(tt.tag))
+		})
+	}
+}
+
+func Test_partialValue(t *testing.T) {
+	str := "4111111111111111"
+
+	tests := map[string]struct {
+		field        Field
+		value        any
+		expVisible   string
+		expMaskFirst bool
+		expOK        bool
+	}{
+		"last4_string": {
+			field:        Field{Action: ActionMaskLast, Keep: 4},
+			value:        "4111111111111234",
+			expVisible:   "1234",
+			expMaskFirst: true,
+			expOK:        true,
+		},
+		"first2_string": {
+			field:      Field{Action: ActionMaskFirst, Keep: 2},
+			value:      "Ivan",
+			expVisible: "Iv",
+			expOK:      true,
+		},
+		"first2_multibyte": {
+			field:      Field{Action: ActionMaskFirst, Keep: 2},
+			value:      "Іван",
+			expVisible: "Ів",
+			expOK:      true,
+		},
+		"last4_int": {
+			field:        Field{Action: ActionMaskLast, Keep: 4},
+			value:        int64(380501234567),
+			expVisible:   "4567",
+			expMaskFirst: true,
+			expOK:        true,
+		},
+		"last2_uint": {
+			field:        Field{Action: ActionMaskLast, Keep: 2},
+			value:        uint(12345),
+			expVisible:   "45",
+			expMaskFirst: true,
+			expOK:        true,
+		},
+		"first1_float": {
+			field:      Field{Action: ActionMaskFirst, Keep: 1},
+			value:      12.5,
+			expVisible: "1",
+			expOK:      true,
+		},
+		"pointer": {
+			field:        Field{Action: ActionMaskLast, Keep: 4},
+			value:        &str,
+			expVisible:   "1111",
+			expMaskFirst: true,
+			expOK:        true,
+		},
+		"nil_pointer": {
+			field: Field{Action: ActionMaskLast, Keep: 4},
+			value: (*string)(nil),
+		},
+		"value_too_short": {
+			field: Field{Action: ActionMaskLast, Keep: 4},
+			value: "1234",
+		},
+		"email": {
+			field:        Field{Action: ActionMaskEmail},
+			value:        "john.doe@example.com",
+			expVisible:   "@example.com",
+			expMaskFirst: true,
+			expOK:        true,
+		},
+		"email_without_at": {
+			field: Field{Action: ActionMaskEmail},
+			value: "john.doe",
+		},
+		"email_without_local_part": {
+			field: Field{Action: ActionMaskEmail},
+			value: "@example.com",
+		},
+		"unsupported_kind": {
+			field: Field{Action: ActionMaskLast, Keep: 1},
+			value: []string{"a", "b"},
+		},
+		"bool": {
+			field: Field{Action: ActionMaskFirst, Keep: 1},
+			value: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN.
+			visible, maskFirst, ok := partialValue(tt.field, reflect.ValueOf(tt.value))
+
+			// THEN.
+			require.Equal(t, tt.expOK, ok)
+			require.Equal(t, tt.expVisible, visible)
+			require.Equal(t, tt.expMaskFirst, maskFirst)
+		})
+	}
+}
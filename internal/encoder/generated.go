@@ -0,0 +1,53 @@
+package encoder
+
+import "reflect"
+
+// GeneratedFunc encodes a value using code generated for its type.
+// The value always implements the interface passed to JSONEncoder.UseGenerated.
+type GeneratedFunc func(b *Buffer, v reflect.Value)
+
+// UseGenerated makes the encoder call fn for structs that implement the given interface instead of
+// encoding them with reflection. Rules that take precedence over the struct encoding (functions registered
+// with RegisterType, the censor.Censorer interface and json.Marshaler) are still applied.
+// Passing a nil function disables generated code. It must be called before the encoder is used.
+func (e *JSONEncoder) UseGenerated(iface reflect.Type, fn GeneratedFunc) {
+	e.generatedType = iface
+	e.generated = fn
+	// Compiled plans may refer to the previous function, so they are built again.
+	e.plans.reset()
+}
+
+// compileGenerated returns an encoder function that uses generated code for structs of the given type.
+// If the type doesn't implement the generatedType interface, the given reflection-based function is returned.
+// If only a pointer to the type implements it, generated code is used only for addressable values.
+func (e *JSONEncoder) compileGenerated(t reflect.Type, reflective encoderFunc) encoderFunc {
+	switch {
+	case t.Implements(e.generatedType):
+		return func(b *Buffer, v reflect.Value) {
+			if !v.CanInterface() {
+				reflective(b, v)
+
+				return
+			}
+
+			// A pointer to an addressable value is passed to avoid copying the value.
+			if v.CanAddr() {
+				v = v.Addr()
+			}
+
+			e.generated(b, v)
+		}
+	case reflect.PointerTo(t).Implements(e.generatedType):
+		return func(b *Buffer, v reflect.Value) {
+			if !v.CanAddr() || !v.CanInterface() {
+				reflective(b, v)
+
+				return
+			}
+
+			e.generated(b, v.Addr())
+		}
+	default:
+		return reflective
+	}
+}
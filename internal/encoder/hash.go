@@ -0,0 +1,64 @@
+package encoder
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"sync"
+)
+
+const (
+	// hashPrefix is written before every digest, so hashed values are easy to recognize in the output.
+	hashPrefix = "hmac:"
+	// hashSize is the number of digest bytes that are written to the output.
+	hashSize = 8
+)
+
+// hasher computes truncated HMAC-SHA256 digests of values using a secret key.
+// The same value always produces the same digest for the same key, so hashed values
+// can be correlated across log lines and processes without revealing the original value.
+type hasher struct {
+	key  []byte
+	pool sync.Pool
+}
+
+// newHasher returns a new hasher for the given key.
+// If the key is empty, nil is returned as hashing without a secret key is not secure.
+func newHasher(key string) *hasher {
+	if key == "" {
+		return nil
+	}
+
+	h := &hasher{key: []byte(key)}
+	h.pool.New = func() any {
+		return hmac.New(sha256.New, h.key)
+	}
+
+	return h
+}
+
+// Sum returns a truncated hex-encoded HMAC-SHA256 digest of the given value prefixed with hashPrefix.
+func (h *hasher) Sum(s string) string {
+	sum := h.digest(s)
+
+	return hashPrefix + hex.EncodeToString(sum[:hashSize])
+}
+
+// digest returns the HMAC-SHA256 digest of the given value.
+func (h *hasher) digest(s string) [sha256.Size]byte {
+	mac, ok := h.pool.Get().(hash.Hash)
+	if !ok {
+		mac = hmac.New(sha256.New, h.key)
+	}
+	defer func() {
+		mac.Reset()
+		h.pool.Put(mac)
+	}()
+
+	var sum [sha256.Size]byte
+	mac.Write([]byte(s)) //nolint:errcheck,gosec // hash.Hash.Write never returns an error.
+	mac.Sum(sum[:0])
+
+	return sum
+}
@@ -0,0 +1,56 @@
+package encoder
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newHasher(t *testing.T) {
+	t.Run("empty key", func(t *testing.T) {
+		require.Nil(t, newHasher(""))
+	})
+
+	t.Run("stable digest", func(t *testing.T) {
+		// GIVEN.
+		h1, h2 := newHasher("secret"), newHasher("secret")
+
+		// WHEN.
+		got1, got2 := h1.Sum("user@example.com"), h2.Sum("user@example.com")
+
+		// THEN.
+		require.Equal(t, "hmac:febca656b1fa2234", got1)
+		require.Equal(t, got1, got2)
+		require.Len(t, got1, len(hashPrefix)+hashSize*2)
+	})
+
+	t.Run("different keys and values", func(t *testing.T) {
+		// GIVEN.
+		h1, h2 := newHasher("secret"), newHasher("another secret")
+
+		// WHEN, THEN.
+		require.NotEqual(t, h1.Sum("value"), h2.Sum("value"))
+		require.NotEqual(t, h1.Sum("value"), h1.Sum("value2"))
+	})
+
+	t.Run("concurrent usage", func(t *testing.T) {
+		// GIVEN.
+		h := newHasher("secret")
+		exp := h.Sum("value")
+
+		// WHEN, THEN.
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					assert.Equal(t, exp, h.Sum("value"))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
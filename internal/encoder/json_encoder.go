@@ -1,7 +1,6 @@
 package encoder
 
 import (
-	"bytes"
 	"encoding"
 	"encoding/json"
 	"reflect"
@@ -10,8 +9,6 @@ import (
 	"unicode/utf8"
 
 	"github.com/shopspring/decimal"
-
-	"github.com/vpakhuchyi/censor/internal/cache"
 )
 
 // NewJSONEncoder returns a new instance of JSONEncoder with given configuration.
@@ -22,9 +19,25 @@ func NewJSONEncoder(c Config) *JSONEncoder {
 			ExcludePatterns:     c.ExcludePatterns,
 			MaskValue:           c.MaskValue,
 			UseJSONTagName:      c.UseJSONTagName,
-			structFieldsCache:   cache.NewTypeCache[[]Field](cache.DefaultMaxCacheSize),
-			escapedStringsCache: cache.New[string](cache.DefaultMaxCacheSize),
-			regexpCache:         cache.New[string](cache.DefaultMaxCacheSize),
+			HashExcludePatterns: c.HashExcludePatterns,
+			DefaultFieldAction:  c.DefaultFieldAction,
+			fieldRules:          newFieldRules(c.FieldRules),
+			maskMapKeys:         newMapKeys(c.MaskMapKeys, c.CacheMode, c.CacheSize),
+			displayMapKeys:      newMapKeys(c.DisplayMapKeys, c.CacheMode, c.CacheSize),
+			DenyByDefault:       c.DenyByDefault,
+			BytesFormat:         c.BytesFormat,
+			detectors:           newDetectors(c),
+			MaxDepth:            c.MaxDepth,
+			MaxSliceLength:      c.MaxSliceLength,
+			MaxMapEntries:       c.MaxMapEntries,
+			MaxStringLength:     c.MaxStringLength,
+			MaxOutputBytes:      c.MaxOutputBytes,
+			hasher:              newHasher(c.HashKey),
+			escapedStringsCache: newStringCache[string](c.CacheMode, c.CacheSize),
+			regexpCache:         newStringCache[string](stringsCacheMode(c), c.CacheSize),
+			onMask:              c.OnMask,
+			inspect:             c.Inspect,
+			audited:             c.OnMask != nil || c.Inspect,
 		},
 	}
 
@@ -35,114 +48,292 @@ func NewJSONEncoder(c Config) *JSONEncoder {
 	return e
 }
 
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
 // JSONEncoder is used to encode data to JSON format.
 type JSONEncoder struct {
 	baseEncoder
+
+	// generatedType is an interface implemented by types with generated encoding code.
+	generatedType reflect.Type
+	// generated encodes values of types that implement generatedType. It's nil if generated code is not used.
+	generated GeneratedFunc
 }
 
-//nolint:exhaustive,gocyclo
-func (e *JSONEncoder) Encode(b *bytes.Buffer, f reflect.Value) {
+// Encode encodes the given value. Functions registered with RegisterType take precedence over the default rules.
+// Values that implement the censor.Censorer interface are replaced with the value returned by CensorValue.
+func (e *JSONEncoder) Encode(b *Buffer, f reflect.Value) {
+	b.flush()
+
 	if !f.IsValid() {
 		b.WriteString("null")
 
 		return
 	}
 
-	switch k := f.Kind(); k {
-	case reflect.Struct:
-		if f.CanInterface() {
-			// If a field implements json.Marshaler interface, then it should be marshaled to string.
-			v, ok := f.Interface().(json.Marshaler)
-			if ok {
-				b.WriteString(PrepareJSONMarshalerValue(v))
+	e.plan(f.Type()).encode(b, f)
+}
 
-				return
+// plan returns a compiled plan for the given type.
+func (e *JSONEncoder) plan(t reflect.Type) *typePlan {
+	return e.plans.get(t, e.compile)
+}
+
+// compile builds a plan for the given type.
+func (e *JSONEncoder) compile(t reflect.Type) typePlan {
+	kind := e.compileKind(t)
+	if e.DenyByDefault && isLoose(t.Kind()) {
+		kind = denyLoose(kind, e.reportedMask(t, ReasonDenyByDefault, e.writeMask))
+	}
+	if isLoose(t.Kind()) || isBytes(t) && e.BytesFormat != BytesNumbers {
+		kind = e.inspected(t, kind)
+	}
+
+	next := kind
+	if t.Kind() == reflect.Struct && t.Implements(jsonMarshalerType) {
+		// If a struct implements json.Marshaler interface, then it should be marshaled to string.
+		// Strings of the output are censored as any other strings.
+		next = func(b *Buffer, v reflect.Value) {
+			if v.CanInterface() {
+				if m, ok := v.Interface().(json.Marshaler); ok {
+					e.writeMarshaledJSON(b, PrepareJSONMarshalerValue(m))
+
+					return
+				}
 			}
+
+			kind(b, v)
+		}
+		next = e.inspected(t, next)
+	}
+
+	return typePlan{
+		encode: e.withCustomRules(t, next, e.Encode),
+		kind:   kind,
+	}
+}
+
+//nolint:exhaustive
+func (e *JSONEncoder) compileKind(t reflect.Type) encoderFunc {
+	switch t.Kind() {
+	case reflect.Struct:
+		if e.generated != nil {
+			return e.compileGenerated(t, e.compileStruct(t))
 		}
 
-		e.Struct(b, f)
+		return e.compileStruct(t)
 	case reflect.Slice, reflect.Array:
-		e.Slice(b, f)
+		if enc := e.compileBytes(t); enc != nil {
+			return enc
+		}
+
+		return e.compileSlice(t)
 	case reflect.Pointer:
-		e.Ptr(b, f)
+		return e.compilePtr(t)
 	case reflect.Map:
-		e.Map(b, f)
+		return e.compileMap(t)
 	case reflect.Interface:
-		e.Interface(b, f)
-	case reflect.Bool:
-		b.WriteString(strconv.FormatBool(f.Bool()))
+		return e.Interface
 	case reflect.String:
-		e.StringEscaped(b, f.String())
-	case reflect.Float32:
-		b.WriteString(decimal.NewFromFloat32(float32(f.Float())).String())
-	case reflect.Float64:
-		b.WriteString(decimal.NewFromFloat(f.Float()).String())
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		b.WriteString(strconv.FormatInt(f.Int(), 10))
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		b.WriteString(strconv.FormatUint(f.Uint(), 10))
-	default:
-		b.WriteString(`"` + unsupportedTypeTmpl + k.String() + `"`)
+		return func(b *Buffer, v reflect.Value) {
+			e.StringValue(b, v.String())
+		}
+	}
+
+	if enc := scalarEncoder(t.Kind()); enc != nil {
+		return enc
+	}
+
+	unsupported := `"` + unsupportedTypeTmpl + t.Kind().String() + `"`
+
+	return func(b *Buffer, _ reflect.Value) {
+		e.report(b, "", ReasonUnsupportedType, "", t)
+		b.WriteString(unsupported)
 	}
 }
 
 // Struct encodes a struct value to JSON format.
-// Note: this method panics if the provided value is not a map.
-func (e *JSONEncoder) Struct(b *bytes.Buffer, v reflect.Value) {
+// Note: this method panics if the provided value is not a struct.
+func (e *JSONEncoder) Struct(b *Buffer, v reflect.Value) {
 	if v.Kind() != reflect.Struct {
 		panic("provided value is not a struct")
 	}
 
-	t := v.Type()
-	var fields []Field
+	e.plan(v.Type()).kind(b, v)
+}
 
-	if t.PkgPath() == "" {
-		fields = e.getStructFields(v)
-	} else {
-		var found bool
-		fields, found = e.structFieldsCache.Get(t)
-		if !found {
-			fields = e.getStructFields(v)
-			e.structFieldsCache.Set(t, fields)
+// compileStruct returns an encoder function for structs of the given type.
+// Fields are parsed and plans of displayed fields are resolved only once.
+//
+//nolint:gocognit
+func (e *JSONEncoder) compileStruct(t reflect.Type) encoderFunc {
+	fields := e.getStructFields(t)
+	keys := make([]string, len(fields))
+	plans := make([]*typePlan, len(fields))
+	for i, field := range fields {
+		keys[i] = `"` + field.Name + `": `
+		if field.Name != "" && field.Action == ActionDisplay {
+			plans[i] = e.plan(t.Field(i).Type)
 		}
 	}
+	audit := e.newStructAudit(t)
 
-	b.WriteByte('{')
+	return func(b *Buffer, v reflect.Value) {
+		if !b.descend(e.MaxDepth) {
+			b.WriteString(jsonTruncationMarker)
 
-	firstField := true
-	for i, field := range fields {
-		if field.Name == "" {
-			continue
+			return
 		}
+		defer b.ascend()
+		defer b.popPath(audit.enter(&e.baseEncoder, b))
 
-		if !firstField {
-			b.WriteByte(',')
+		b.WriteByte('{')
+
+		var written int
+		for i, field := range fields {
+			if field.Name == "" {
+				continue
+			}
+
+			if field.Action == ActionOmit {
+				audit.report(&e.baseEncoder, b, v, i)
+
+				continue
+			}
+
+			if written != 0 {
+				b.WriteByte(',')
+			}
+
+			if e.truncate(b, written, 0) {
+				b.WriteString(`"` + truncationMarker + `": "`)
+				b.WriteString(moreCount(countFields(fields[i:])))
+				b.WriteByte('"')
+
+				break
+			}
+			written++
+
+			b.WriteString(keys[i])
+
+			if field.Action == ActionDisplay {
+				b.flush()
+				shown, n := b.show(), audit.push(&e.baseEncoder, b, i)
+				plans[i].encode(b, v.Field(i))
+				b.shown = shown
+				b.popPath(n)
+			} else {
+				audit.report(&e.baseEncoder, b, v, i)
+				e.FieldValue(b, field, v.Field(i))
+			}
 		}
-		firstField = false
+		b.WriteByte('}')
+	}
+}
 
-		b.WriteByte('"')
-		b.WriteString(field.Name)
-		b.WriteString(`": `)
+// FieldValue encodes a value of a struct field according to the field action.
+// Values of fields with ActionOmit must not be encoded at all.
+func (e *JSONEncoder) FieldValue(b *Buffer, f Field, v reflect.Value) {
+	switch f.Action {
+	case ActionDisplay:
+		shown := b.show()
+		e.Encode(b, v)
+		b.shown = shown
+	case ActionMask, ActionOmit:
+		e.writeMask(b)
+	case ActionHash:
+		e.writeHashed(b, v)
+	default:
+		e.writePartiallyMasked(b, f, v)
+	}
+}
 
-		if field.IsMasked {
-			b.WriteByte('"')
-			b.WriteString(e.MaskValue)
-			b.WriteByte('"')
-		} else {
-			e.Encode(b, v.Field(i))
+// FieldString is the same as FieldValue, but for struct fields of the string type.
+// Unlike FieldValue, it doesn't apply functions registered for the string type.
+func (e *JSONEncoder) FieldString(b *Buffer, f Field, s string) {
+	switch f.Action {
+	case ActionDisplay:
+		e.StringValue(b, s)
+	case ActionMask, ActionOmit:
+		e.writeMask(b)
+	case ActionHash:
+		if e.hasher == nil {
+			e.writeMask(b)
+
+			return
 		}
+
+		b.WriteByte('"')
+		b.WriteString(e.hasher.Sum(s))
+		b.WriteByte('"')
+	default:
+		visible, maskFirst, ok := partialString(f, s)
+		e.writePartial(b, visible, maskFirst, ok)
+	}
+}
+
+// writeMask writes the mask value as a JSON string.
+func (e *JSONEncoder) writeMask(b *Buffer) {
+	b.WriteByte('"')
+	b.WriteString(e.MaskValue)
+	b.WriteByte('"')
+}
+
+// writeHashed writes a digest of the given value as a JSON string.
+// If the value can't be hashed, it's masked entirely.
+func (e *JSONEncoder) writeHashed(b *Buffer, v reflect.Value) {
+	digest, ok := e.hashedValue(v)
+	if !ok {
+		e.writeMask(b)
+
+		return
+	}
+
+	b.WriteByte('"')
+	b.WriteString(digest)
+	b.WriteByte('"')
+}
+
+// writePartiallyMasked writes a value masked with one of the partial masking actions as a JSON string.
+// The visible part is still checked against the configured exclusion patterns.
+// If the value can't be partially masked, it's masked entirely.
+func (e *JSONEncoder) writePartiallyMasked(b *Buffer, f Field, v reflect.Value) {
+	visible, maskFirst, ok := partialValue(f, v)
+	e.writePartial(b, visible, maskFirst, ok)
+}
+
+// writePartial writes the visible part of a partially masked value along with the mask as a JSON string.
+// If ok is false, the value is masked entirely.
+func (e *JSONEncoder) writePartial(b *Buffer, visible string, maskFirst, ok bool) {
+	if !ok {
+		e.writeMask(b)
+
+		return
+	}
+
+	b.WriteByte('"')
+	if maskFirst {
+		e.escapeString(b, e.MaskValue)
+		e.writeEscapedCensoredString(b, visible)
+	} else {
+		e.writeEscapedCensoredString(b, visible)
+		e.escapeString(b, e.MaskValue)
 	}
-	b.WriteByte('}')
+	b.WriteByte('"')
 }
 
+// getStructFields returns a slice of fields aligned with the struct field indexes.
+// Unexported fields are left with an empty name and are skipped during encoding.
+//
 //nolint:gocognit
-func (e *JSONEncoder) getStructFields(v reflect.Value) []Field {
-	numFields := v.NumField()
+func (e *JSONEncoder) getStructFields(t reflect.Type) []Field {
+	numFields := t.NumField()
 	fields := make([]Field, numFields)
+	typeName := TypeName(t)
 
 	for i := 0; i < numFields; i++ {
-		field := v.Type().Field(i)
-		if !v.Field(i).CanInterface() {
+		field := t.Field(i)
+		if !field.IsExported() {
 			continue
 		}
 
@@ -165,9 +356,11 @@ func (e *JSONEncoder) getStructFields(v reflect.Value) []Field {
 			}
 		}
 
+		action, keep := e.FieldAction(typeName, field.Name, field.Tag.Get(e.CensorFieldTag))
 		fields[i] = Field{
-			Name:     name,
-			IsMasked: field.Tag.Get(e.CensorFieldTag) != display,
+			Name:   name,
+			Action: action,
+			Keep:   keep,
 		}
 	}
 
@@ -176,61 +369,139 @@ func (e *JSONEncoder) getStructFields(v reflect.Value) []Field {
 
 // Map encodes a map value to JSON format.
 // Note: this method panics if the provided value is not a map.
-func (e *JSONEncoder) Map(b *bytes.Buffer, v reflect.Value) {
+func (e *JSONEncoder) Map(b *Buffer, v reflect.Value) {
 	if v.Kind() != reflect.Map {
 		panic("provided value is not a map")
 	}
 
-	if v.IsNil() {
-		b.WriteString("null")
+	e.plan(v.Type()).kind(b, v)
+}
 
-		return
-	}
+// compileMap returns an encoder function for maps of the given type.
+// Values of keys matched by the MaskMapKeys patterns are masked. In the deny-by-default mode,
+// only values of keys matched by the DisplayMapKeys patterns are displayed.
+//
+//nolint:gocognit
+func (e *JSONEncoder) compileMap(t reflect.Type) encoderFunc {
+	elem := e.plan(t.Elem())
+	checkKeys := e.maskMapKeys.checks(t.Key())
+	allowKeys := e.displayMapKeys.checks(t.Key())
+
+	return func(b *Buffer, v reflect.Value) {
+		if v.IsNil() {
+			b.WriteString("null")
+
+			return
+		}
 
-	b.WriteByte('{')
+		if !b.enter(v) {
+			b.WriteString(jsonCycleMarker)
 
-	first := true
-	for iter := v.MapRange(); iter.Next(); {
-		if !first {
-			b.WriteByte(',')
+			return
 		}
-		first = false
+		defer b.leave()
 
-		key, value := iter.Key(), iter.Value()
+		if !b.descend(e.MaxDepth) {
+			b.WriteString(jsonTruncationMarker)
 
-		e.encodeMapKey(b, key)
-		b.WriteByte(':')
-		e.Encode(b, value)
+			return
+		}
+		defer b.ascend()
+
+		b.WriteByte('{')
+
+		var i int
+		for iter := v.MapRange(); iter.Next(); i++ {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+
+			if e.truncate(b, i, e.MaxMapEntries) {
+				b.WriteString(`"` + truncationMarker + `":"`)
+				b.WriteString(moreCount(v.Len() - i))
+				b.WriteByte('"')
+
+				break
+			}
+
+			e.encodeMapKey(b, iter.Key())
+			b.WriteByte(':')
+			b.flush()
+			if reason := e.maskMapValue(iter.Key(), checkKeys, allowKeys); reason != "" {
+				e.report(b, "[]", reason, "", t.Elem())
+				e.writeMask(b)
+			} else {
+				shown, n := b.show(), e.pushPath(b, "[]")
+				elem.encode(b, iter.Value())
+				b.shown = shown
+				b.popPath(n)
+			}
+		}
+		b.WriteByte('}')
 	}
-	b.WriteByte('}')
 }
 
 // Slice encodes a slice value to JSON format.
 // This function is also can be used to parse an array.
 // Note: this method panics if the provided value is not a slice or array.
-func (e *JSONEncoder) Slice(b *bytes.Buffer, v reflect.Value) {
+func (e *JSONEncoder) Slice(b *Buffer, v reflect.Value) {
 	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
 		panic("provided value is not a slice/array")
 	}
 
-	b.WriteByte('[')
-	length := v.Len()
-	for i := 0; i < length; i++ {
-		e.Encode(b, v.Index(i))
+	e.plan(v.Type()).kind(b, v)
+}
+
+// compileSlice returns an encoder function for slices or arrays of the given type.
+func (e *JSONEncoder) compileSlice(t reflect.Type) encoderFunc {
+	elem := e.plan(t.Elem())
+	isSlice := t.Kind() == reflect.Slice
+
+	return func(b *Buffer, v reflect.Value) {
+		if isSlice && !v.IsNil() {
+			if !b.enter(v) {
+				b.WriteString(jsonCycleMarker)
+
+				return
+			}
+			defer b.leave()
+		}
+
+		if !b.descend(e.MaxDepth) {
+			b.WriteString(jsonTruncationMarker)
+
+			return
+		}
+		defer b.ascend()
+
+		b.WriteByte('[')
+		length := v.Len()
+		for i := 0; i < length; i++ {
+			if i != 0 {
+				b.WriteByte(',')
+				b.WriteByte(' ')
+			}
+
+			if e.truncate(b, i, e.MaxSliceLength) {
+				b.WriteString(`"` + moreMarker(length-i) + `"`)
 
-		if i < length-1 {
-			b.WriteByte(',')
-			b.WriteByte(' ')
+				break
+			}
+
+			b.flush()
+			n := e.pushPath(b, "[]")
+			elem.encode(b, v.Index(i))
+			b.popPath(n)
 		}
+		b.WriteByte(']')
 	}
-	b.WriteByte(']')
 }
 
 // Interface encodes an interface value to JSON format.
 // In case of a pointer to unsupported type of value, a string built from unsupportedTypeTmpl
 // is used instead of the real value. That string contains a type of the value.
 // Note: this method panics if the provided value is not an interface.
-func (e *JSONEncoder) Interface(b *bytes.Buffer, v reflect.Value) {
+func (e *JSONEncoder) Interface(b *Buffer, v reflect.Value) {
 	if v.Kind() != reflect.Interface {
 		panic("provided value is not an interface")
 	}
@@ -246,38 +517,78 @@ func (e *JSONEncoder) Interface(b *bytes.Buffer, v reflect.Value) {
 // Ptr encodes a pointer value to JSON format.
 // In case of a pointer to unsupported type of value, a string built from unsupportedTypeTmpl
 // is used instead of the real value. That string contains a type of the value.
+// A pointer that refers to a value that is being encoded is replaced with jsonCycleMarker.
 // Note: this method panics if the provided value is not a pointer.
-func (e *JSONEncoder) Ptr(b *bytes.Buffer, v reflect.Value) {
+func (e *JSONEncoder) Ptr(b *Buffer, v reflect.Value) {
 	if v.Kind() != reflect.Pointer {
 		panic("provided value is not a pointer")
 	}
 
-	if v.IsNil() {
-		b.WriteString("null")
+	e.plan(v.Type()).kind(b, v)
+}
 
-		return
-	}
+// compilePtr returns an encoder function for pointers of the given type.
+func (e *JSONEncoder) compilePtr(t reflect.Type) encoderFunc {
+	elem := e.plan(t.Elem())
 
-	e.Encode(b, v.Elem())
+	return func(b *Buffer, v reflect.Value) {
+		if v.IsNil() {
+			b.WriteString("null")
+
+			return
+		}
+
+		if !b.enter(v) {
+			b.WriteString(jsonCycleMarker)
+
+			return
+		}
+
+		elem.encode(b, v.Elem())
+		b.leave()
+	}
 }
 
 // String encodes the input string by masking any substrings that match the configured exclusion patterns.
 // It replaces matched segments with a predefined mask value to censor sensitive information.
-func (e *JSONEncoder) String(b *bytes.Buffer, s string) {
+func (e *JSONEncoder) String(b *Buffer, s string) {
 	e.WriteString(b, s)
 }
 
+// StringValue encodes a string value as a JSON string the same way as it's encoded as a part of other values:
+// sensitive parts are masked and the string is truncated if it exceeds MaxStringLength.
+func (e *JSONEncoder) StringValue(b *Buffer, s string) {
+	if e.isLongString(s) {
+		e.writeTruncatedString(b, s)
+	} else {
+		e.StringEscaped(b, s)
+	}
+}
+
 // StringEscaped encodes and escapes the input string by masking any substrings that match the configured exclusion patterns.
 // It replaces matched segments with a predefined mask value to censor sensitive information.
-func (e *JSONEncoder) StringEscaped(b *bytes.Buffer, s string) {
+func (e *JSONEncoder) StringEscaped(b *Buffer, s string) {
 	b.WriteByte('"')
 	e.writeEscapedCensoredString(b, s)
 	b.WriteByte('"')
 }
 
+// writeTruncatedString writes a string that exceeds MaxStringLength as a JSON string
+// followed by a marker that says how many characters were cut off.
+func (e *JSONEncoder) writeTruncatedString(b *Buffer, s string) {
+	truncated, n := e.censorAndTruncate(b, s)
+
+	b.WriteByte('"')
+	e.escapeString(b, truncated)
+	if n > 0 {
+		b.WriteString(moreMarker(n))
+	}
+	b.WriteByte('"')
+}
+
 // writeEscapedCensoredString applies censoring and escaping in one pass.
-func (e *JSONEncoder) writeEscapedCensoredString(b *bytes.Buffer, s string) {
-	if len(e.ExcludePatterns) == 0 || e.ExcludePatternsCompiled == nil {
+func (e *JSONEncoder) writeEscapedCensoredString(b *Buffer, s string) {
+	if !e.censorsStrings() {
 		e.escapeString(b, s)
 
 		return
@@ -285,25 +596,25 @@ func (e *JSONEncoder) writeEscapedCensoredString(b *bytes.Buffer, s string) {
 
 	cached, ok := e.regexpCache.Get(s)
 	if ok {
-		e.escapeStringWithCache(b, cached)
+		e.escapeStringWithCache(b, cached, e.secrets.matcher.Load())
 
 		return
 	}
 
-	matches := e.ExcludePatternsCompiled.FindAllStringIndex(s, -1)
+	secrets := e.secrets.matcher.Load()
+	matches := e.findMatches(b, s, secrets)
 	if len(matches) == 0 {
-		e.escapeStringWithCache(b, s)
-		e.regexpCache.Set(s, s)
+		e.escapeStringWithCache(b, s, secrets)
+		e.cacheString(e.regexpCache, s, s, secrets)
 
 		return
 	}
 
 	lastIndex := 0
 	for _, m := range matches {
-		start, end := m[0], m[1]
-		e.escapeString(b, s[lastIndex:start])
-		e.escapeString(b, e.MaskValue)
-		lastIndex = end
+		e.escapeString(b, s[lastIndex:m.start])
+		e.escapeString(b, e.replacement(s, m))
+		lastIndex = m.end
 	}
 	e.escapeString(b, s[lastIndex:])
 }
@@ -314,7 +625,7 @@ func (e *JSONEncoder) writeEscapedCensoredString(b *bytes.Buffer, s string) {
 // with the Unicode replacement character if invalid.
 //
 //nolint:gocyclo,mnd
-func (e *JSONEncoder) escapeString(b *bytes.Buffer, s string) {
+func (e *JSONEncoder) escapeString(b *Buffer, s string) {
 	for _, r := range s {
 		switch r {
 		case '\\', '"':
@@ -353,7 +664,8 @@ func (e *JSONEncoder) escapeString(b *bytes.Buffer, s string) {
 }
 
 // escapeStringWithCache escapes string content with caching.
-func (e *JSONEncoder) escapeStringWithCache(b *bytes.Buffer, s string) {
+// The given secrets matcher is the one the string was checked against, see cacheString.
+func (e *JSONEncoder) escapeStringWithCache(b *Buffer, s string, secrets *secretMatcher) {
 	if cached, ok := e.escapedStringsCache.Get(s); ok {
 		b.WriteString(cached)
 
@@ -363,12 +675,14 @@ func (e *JSONEncoder) escapeStringWithCache(b *bytes.Buffer, s string) {
 	startLen := b.Len()
 	e.escapeString(b, s)
 
-	escaped := b.String()[startLen:]
-	e.escapedStringsCache.Set(s, escaped)
+	e.cacheString(e.escapedStringsCache, s, b.String()[startLen:], secrets)
 }
 
+// encodeMapKey encodes a map key as a member name. String keys are censored, but never truncated,
+// so keys with a common prefix don't become duplicate members.
+//
 //nolint:exhaustive
-func (e *JSONEncoder) encodeMapKey(b *bytes.Buffer, f reflect.Value) {
+func (e *JSONEncoder) encodeMapKey(b *Buffer, f reflect.Value) {
 	switch k := f.Kind(); k {
 	case reflect.String:
 		e.StringEscaped(b, f.String())
@@ -382,7 +696,7 @@ func (e *JSONEncoder) encodeMapKey(b *bytes.Buffer, f reflect.Value) {
 		b.WriteString(strconv.FormatUint(f.Uint(), 10))
 	default:
 		if v, ok := f.Interface().(encoding.TextMarshaler); ok {
-			b.WriteString(PrepareTextMarshalerValue(v))
+			e.WriteString(b, PrepareTextMarshalerValue(v))
 		} else {
 			b.WriteString(unsupportedTypeTmpl + k.String())
 		}
@@ -8,8 +8,6 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
-
-	"github.com/vpakhuchyi/censor/internal/cache"
 )
 
 func TestJSONEncoder_NewJSONEncoder(t *testing.T) {
@@ -20,9 +18,8 @@ func TestJSONEncoder_NewJSONEncoder(t *testing.T) {
 		baseEncoder: baseEncoder{
 			CensorFieldTag:      defaultCensorFieldTag,
 			MaskValue:           "[CENSORED]",
-			structFieldsCache:   cache.NewTypeCache[[]Field](cache.DefaultMaxCacheSize),
-			escapedStringsCache: cache.New[string](cache.DefaultMaxCacheSize),
-			regexpCache:         cache.New[string](cache.DefaultMaxCacheSize),
+			escapedStringsCache: newStringCache[string](CachePlain, 0),
+			regexpCache:         newStringCache[string](CachePlain, 0),
 		},
 	}
 	require.EqualValues(t, exp, got)
@@ -126,7 +123,7 @@ func TestJSONEncoder_Encode(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN.
-		e.Encode(&b, reflect.ValueOf(p))
+		e.Encode(NewBuffer(&b), reflect.ValueOf(p))
 
 		// THEN.
 		exp := `{"String": "st\"ring","StringMasked": "[CENSORED]","StringWithRegexp": "%[CENSORED]#",` +
@@ -153,7 +150,7 @@ func TestJSONEncoder_Struct(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Struct(&b, reflect.ValueOf(v))
+			e.Struct(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -175,7 +172,7 @@ func TestJSONEncoder_Struct(t *testing.T) {
 			}{}
 
 			// WHEN.
-			e.Struct(&b, reflect.ValueOf(s))
+			e.Struct(NewBuffer(&b), reflect.ValueOf(s))
 
 			// THEN.
 			// Unexported fields are now skipped entirely, so we get an empty struct
@@ -207,7 +204,7 @@ func TestJSONEncoder_Struct_UnexportedFieldsBehavior(t *testing.T) {
 		}
 
 		// WHEN
-		e.Struct(&b, reflect.ValueOf(s))
+		e.Struct(NewBuffer(&b), reflect.ValueOf(s))
 
 		// THEN: Only exported fields appear in output
 		exp := `{"PublicField": "visible","AnotherPublic": 42}`
@@ -228,7 +225,7 @@ func TestJSONEncoder_Struct_UnexportedFieldsBehavior(t *testing.T) {
 		s := AllPrivate{field1: "hidden", field2: 123}
 
 		// WHEN
-		e.Struct(&b, reflect.ValueOf(s))
+		e.Struct(NewBuffer(&b), reflect.ValueOf(s))
 
 		// THEN: Empty struct
 		exp := "{}"
@@ -249,7 +246,7 @@ func TestJSONEncoder_Struct_UnexportedFieldsBehavior(t *testing.T) {
 		s := SecureConfig{APIKey: "secret123", privateKey: "hidden"}
 
 		// WHEN
-		e.Struct(&b, reflect.ValueOf(s))
+		e.Struct(NewBuffer(&b), reflect.ValueOf(s))
 
 		// THEN: APIKey is masked but present, privateKey is completely skipped
 		exp := `{"APIKey": "[CENSORED]"}`
@@ -267,7 +264,7 @@ func TestJSONEncoder_Map(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -287,7 +284,7 @@ func TestJSONEncoder_Map(t *testing.T) {
 			}
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -307,7 +304,7 @@ func TestJSONEncoder_Map(t *testing.T) {
 			}
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			got := b.String()
@@ -326,7 +323,7 @@ func TestJSONEncoder_Map(t *testing.T) {
 			var v map[string]string
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			exp := "null"
@@ -345,7 +342,7 @@ func TestJSONEncoder_Slice(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Slice(&b, reflect.ValueOf(v))
+			e.Slice(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -363,7 +360,7 @@ func TestJSONEncoder_Interface(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Interface(&b, reflect.ValueOf(v))
+			e.Interface(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -384,7 +381,7 @@ func TestJSONEncoder_Ptr(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Ptr(&b, reflect.ValueOf(v))
+			e.Ptr(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -400,7 +397,7 @@ func TestJSONEncoder_Ptr(t *testing.T) {
 			var v *string
 
 			// WHEN.
-			e.Ptr(&b, reflect.ValueOf(v))
+			e.Ptr(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -416,7 +413,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "hello")
+		e.escapeString(NewBuffer(&b), "hello")
 
 		// THEN: The string should be written as-is.
 		require.Equal(t, "hello", b.String())
@@ -429,7 +426,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, `"`)
+		e.escapeString(NewBuffer(&b), `"`)
 
 		// THEN: The quote should be escaped.
 		require.Equal(t, `\"`, b.String())
@@ -442,7 +439,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, `\`)
+		e.escapeString(NewBuffer(&b), `\`)
 
 		// THEN: The backslash should be escaped.
 		require.Equal(t, `\\`, b.String())
@@ -455,7 +452,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "foo\bbar")
+		e.escapeString(NewBuffer(&b), "foo\bbar")
 
 		// THEN: The backspace should be escaped.
 		require.Equal(t, "foo\\bbar", b.String())
@@ -468,7 +465,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "foo\fbar")
+		e.escapeString(NewBuffer(&b), "foo\fbar")
 
 		// THEN: The form feed should be escaped.
 		require.Equal(t, "foo\\fbar", b.String())
@@ -481,7 +478,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "foo\nbar")
+		e.escapeString(NewBuffer(&b), "foo\nbar")
 
 		// THEN: The newline should be escaped.
 		require.Equal(t, "foo\\nbar", b.String())
@@ -494,7 +491,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "foo\rbar")
+		e.escapeString(NewBuffer(&b), "foo\rbar")
 
 		// THEN: The carriage return should be escaped.
 		require.Equal(t, "foo\\rbar", b.String())
@@ -507,7 +504,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "foo\tbar")
+		e.escapeString(NewBuffer(&b), "foo\tbar")
 
 		// THEN: The tab should be escaped.
 		require.Equal(t, "foo\\tbar", b.String())
@@ -520,7 +517,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, string([]byte{0x01}))
+		e.escapeString(NewBuffer(&b), string([]byte{0x01}))
 
 		// THEN: The control character should be escaped as unicode.
 		require.Equal(t, `\u0001`, b.String())
@@ -533,7 +530,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, string([]byte{0x7F}))
+		e.escapeString(NewBuffer(&b), string([]byte{0x7F}))
 
 		// THEN: The DEL character should be escaped as unicode.
 		require.Equal(t, `\u007f`, b.String())
@@ -546,7 +543,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, string([]byte{0xC0}))
+		e.escapeString(NewBuffer(&b), string([]byte{0xC0}))
 
 		// THEN: The invalid byte should be replaced with the replacement character.
 		require.Equal(t, string([]byte{0xEF, 0xBF, 0xBD}), b.String())
@@ -559,7 +556,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "ñ")
+		e.escapeString(NewBuffer(&b), "ñ")
 
 		// THEN: The non-ASCII character should be escaped as unicode.
 		require.Equal(t, `\u00f1`, b.String())
@@ -572,7 +569,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: escapeString is called.
-		e.escapeString(&b, "\u2028")
+		e.escapeString(NewBuffer(&b), "\u2028")
 
 		// THEN: The line separator should be escaped as unicode.
 		require.Equal(t, `\u2028`, b.String())
@@ -586,7 +583,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		value := "foo\bbar"
 
 		// WHEN: escapeStringWithCache is called.
-		e.escapeStringWithCache(&b, value)
+		e.escapeStringWithCache(NewBuffer(&b), value, nil)
 
 		// THEN: The escaped string should be cached and written to buffer.
 		cacheValue, ok := e.escapedStringsCache.Get(value)
@@ -603,7 +600,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		value := "hello"
 
 		// WHEN: escapeStringWithCache is called with a string that needs no escaping.
-		e.escapeStringWithCache(&b, value)
+		e.escapeStringWithCache(NewBuffer(&b), value, nil)
 
 		// THEN: The original string should be cached and written to buffer.
 		cacheValue, ok := e.escapedStringsCache.Get(value)
@@ -621,7 +618,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		e.escapedStringsCache.Set(s, cached)
 
 		// WHEN: escapeStringWithCache is called.
-		e.escapeStringWithCache(&b, s)
+		e.escapeStringWithCache(NewBuffer(&b), s, nil)
 
 		// THEN: The cached value should be used without re-escaping.
 		require.Equal(t, cached, b.String())
@@ -634,7 +631,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with a simple string.
-		e.StringEscaped(&b, "hello")
+		e.StringEscaped(NewBuffer(&b), "hello")
 
 		// THEN: The string should be wrapped in quotes.
 		require.Equal(t, `"hello"`, b.String())
@@ -647,7 +644,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with an empty string.
-		e.StringEscaped(&b, "")
+		e.StringEscaped(NewBuffer(&b), "")
 
 		// THEN: The result should be empty quotes.
 		require.Equal(t, `""`, b.String())
@@ -660,7 +657,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with a string containing quotes.
-		e.StringEscaped(&b, `say "hello"`)
+		e.StringEscaped(NewBuffer(&b), `say "hello"`)
 
 		// THEN: The quotes should be escaped and the string wrapped in quotes.
 		require.Equal(t, `"say \"hello\""`, b.String())
@@ -673,7 +670,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with a string containing a newline.
-		e.StringEscaped(&b, "line1\nline2")
+		e.StringEscaped(NewBuffer(&b), "line1\nline2")
 
 		// THEN: The newline should be escaped and the string wrapped in quotes.
 		require.Equal(t, `"line1\nline2"`, b.String())
@@ -686,7 +683,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with a unicode string.
-		e.StringEscaped(&b, "café")
+		e.StringEscaped(NewBuffer(&b), "café")
 
 		// THEN: The unicode characters should be escaped and the string wrapped in quotes.
 		require.Equal(t, `"caf\u00e9"`, b.String())
@@ -702,7 +699,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with a string containing an email.
-		e.StringEscaped(&b, "contact: user@example.com")
+		e.StringEscaped(NewBuffer(&b), "contact: user@example.com")
 
 		// THEN: The email should be censored and the string wrapped in quotes.
 		require.Equal(t, `"contact: [CENSORED]"`, b.String())
@@ -718,7 +715,7 @@ func TestJSONEncoder_StringEscaped(t *testing.T) {
 		defer b.Reset()
 
 		// WHEN: StringEscaped is called with a string containing both email and quotes.
-		e.StringEscaped(&b, "say \"email: user@example.com\"")
+		e.StringEscaped(NewBuffer(&b), "say \"email: user@example.com\"")
 
 		// THEN: The email should be censored, quotes escaped, and string wrapped in quotes.
 		require.Equal(t, `"say \"email: [CENSORED]\""`, b.String())
@@ -745,7 +742,7 @@ func TestJSONEncoder_Struct_UseJSONTagName(t *testing.T) {
 		var b bytes.Buffer
 		defer b.Reset()
 
-		e.Struct(&b, reflect.ValueOf(value))
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
 
 		require.Equal(t, `{"Tagged": "tagged","Skipped": "skipped","Empty": "empty","Default": "default"}`, b.String())
 	})
@@ -755,8 +752,160 @@ func TestJSONEncoder_Struct_UseJSONTagName(t *testing.T) {
 		var b bytes.Buffer
 		defer b.Reset()
 
-		e.Struct(&b, reflect.ValueOf(value))
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
 
 		require.Equal(t, `{"alias": "tagged","Empty": "empty","Default": "default"}`, b.String())
 	})
 }
+
+func TestJSONEncoder_Struct_PartialMasking(t *testing.T) {
+	type payload struct {
+		Card     string `censor:"mask=last4"`
+		Name     string `censor:"mask=first2"`
+		Email    string `censor:"mask=email"`
+		Phone    int64  `censor:"mask=last2"`
+		Short    string `censor:"mask=last4"`
+		Invalid  string `censor:"mask=email"`
+		Nested   []int  `censor:"mask=last1"`
+		Password string `censor:"mask"`
+	}
+
+	value := payload{
+		Card:     "4111111111111234",
+		Name:     "Ivan",
+		Email:    "john.doe@example.com",
+		Phone:    380501234567,
+		Short:    "1234",
+		Invalid:  "john.doe",
+		Nested:   []int{1, 2},
+		Password: "secret",
+	}
+
+	t.Run("partial masks", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		exp := `{"Card": "[CENSORED]1234","Name": "Iv[CENSORED]","Email": "[CENSORED]@example.com",` +
+			`"Phone": "[CENSORED]67","Short": "[CENSORED]","Invalid": "[CENSORED]","Nested": "[CENSORED]",` +
+			`"Password": "[CENSORED]"}`
+		require.Equal(t, exp, b.String())
+	})
+
+	t.Run("visible part is checked against exclude patterns", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "***", ExcludePatterns: []string{`example\.com`}})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(struct {
+			Email string `censor:"mask=email"`
+		}{Email: "john@example.com"}))
+
+		// THEN.
+		require.Equal(t, `{"Email": "***@***"}`, b.String())
+	})
+}
+
+func TestJSONEncoder_Hashing(t *testing.T) {
+	type payload struct {
+		UserID int64  `censor:"hash"`
+		Email  string `censor:"hash"`
+		Tags   []int  `censor:"hash"`
+		Note   string `censor:"display"`
+	}
+
+	value := payload{UserID: 42, Email: "user@example.com", Tags: []int{1}, Note: "mail user@example.com"}
+	h := newHasher("secret")
+
+	t.Run("hash tag and hashed exclude patterns", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{
+			MaskValue:           "[CENSORED]",
+			HashKey:             "secret",
+			HashExcludePatterns: true,
+			ExcludePatterns:     []string{`\S+@\S+`},
+		})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		exp := `{"UserID": "` + h.Sum("42") + `","Email": "` + h.Sum("user@example.com") + `",` +
+			`"Tags": "[CENSORED]","Note": "mail ` + h.Sum("user@example.com") + `"}`
+		require.Equal(t, exp, b.String())
+	})
+
+	t.Run("no hash key", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]", HashExcludePatterns: true, ExcludePatterns: []string{`\S+@\S+`}})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		exp := `{"UserID": "[CENSORED]","Email": "[CENSORED]","Tags": "[CENSORED]","Note": "mail [CENSORED]"}`
+		require.Equal(t, exp, b.String())
+	})
+}
+
+func TestJSONEncoder_Struct_Omit(t *testing.T) {
+	type payload struct {
+		Password string `censor:"omit"`
+		ID       string `censor:"display"`
+		Token    string `censor:"omit"`
+		Email    string
+		Secret   string `censor:"omit"`
+	}
+
+	value := payload{Password: "pass", ID: "42", Token: "token", Email: "user@example.com", Secret: "secret"}
+
+	t.Run("omit tag", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		require.Equal(t, `{"ID": "42","Email": "[CENSORED]"}`, b.String())
+	})
+
+	t.Run("omit by default", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]", DefaultFieldAction: ActionOmit})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		require.Equal(t, `{"ID": "42"}`, b.String())
+	})
+
+	t.Run("all fields omitted", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]", DefaultFieldAction: ActionOmit})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(struct{ A, B string }{A: "a", B: "b"}))
+
+		// THEN.
+		require.Equal(t, `{}`, b.String())
+	})
+}
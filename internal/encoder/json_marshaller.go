@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"encoding/json"
+	"strings"
 )
 
 // PrepareJSONMarshalerValue marshals a value that implements [json.Marshaler] interface to string.
@@ -13,3 +14,53 @@ func PrepareJSONMarshalerValue(jm json.Marshaler) string {
 
 	return string(data)
 }
+
+// writeMarshaledJSON writes the output of a json.Marshaler. Sensitive parts of its string tokens are masked,
+// while the rest of the output is written as is, so it stays valid JSON. Output that isn't valid JSON,
+// e.g. an error, is censored as a whole.
+func (e *JSONEncoder) writeMarshaledJSON(b *Buffer, data string) {
+	if !e.censorsStrings() {
+		b.WriteString(data)
+
+		return
+	}
+
+	if !json.Valid([]byte(data)) {
+		e.WriteString(b, data)
+
+		return
+	}
+
+	for {
+		start := strings.IndexByte(data, '"')
+		if start < 0 {
+			b.WriteString(data)
+
+			return
+		}
+
+		end := stringTokenEnd(data, start)
+		b.WriteString(data[:start])
+
+		// The output is valid, so the token can always be decoded.
+		var s string
+		_ = json.Unmarshal([]byte(data[start:end]), &s)
+		e.StringEscaped(b, s)
+
+		data = data[end:]
+	}
+}
+
+// stringTokenEnd returns the end of the JSON string token that starts at the given index of data.
+func stringTokenEnd(data string, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return len(data)
+}
@@ -0,0 +1,68 @@
+package encoder
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// truncationMarker is written instead of values that exceed the configured limits.
+const truncationMarker = "..."
+
+// moreMarker returns a marker that says how many elements or characters were cut off, e.g. "...(+123 more)".
+func moreMarker(n int) string {
+	return truncationMarker + moreCount(n)
+}
+
+// moreCount returns a string that says how many elements or characters were cut off, e.g. "(+123 more)".
+func moreCount(n int) string {
+	return "(+" + strconv.Itoa(n) + " more)"
+}
+
+// truncate reports whether the i-th element of a collection must not be written,
+// either because the collection limit or the output size limit is reached.
+func (e *baseEncoder) truncate(b *Buffer, i, limit int) bool {
+	if limit > 0 && i >= limit {
+		return true
+	}
+
+	return e.MaxOutputBytes > 0 && b.written() >= e.MaxOutputBytes
+}
+
+// isLongString reports whether the given string exceeds MaxStringLength.
+func (e *baseEncoder) isLongString(s string) bool {
+	// The byte length is checked first as it's cheap and can't be less than the number of characters.
+	return e.MaxStringLength > 0 && len(s) > e.MaxStringLength && utf8.RuneCountInString(s) > e.MaxStringLength
+}
+
+// censorAndTruncate returns the given string with sensitive parts masked and cut to MaxStringLength characters,
+// along with the number of characters that were cut off. The string is truncated only after masking,
+// so a cut can never prevent an exclude pattern from matching. Matches are reported at the path of b
+// and added to its inspection.
+func (e *baseEncoder) censorAndTruncate(b *Buffer, s string) (string, int) {
+	var buf bytes.Buffer
+	tmp := NewBuffer(&buf)
+	tmp.path, tmp.inspection = b.path, b.inspection
+	e.WriteString(tmp, s)
+	b.inspection = tmp.inspection
+	censored := buf.String()
+
+	n := utf8.RuneCountInString(censored)
+	if n <= e.MaxStringLength {
+		return censored, 0
+	}
+
+	return censored[:runeOffset(censored, e.MaxStringLength)], n - e.MaxStringLength
+}
+
+// countFields returns the number of fields that are written to the output.
+func countFields(fields []Field) int {
+	var n int
+	for _, f := range fields {
+		if f.Name != "" && f.Action != ActionOmit {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,168 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_Limits(t *testing.T) {
+	type inner struct {
+		Values []int `censor:"display"`
+	}
+
+	type payload struct {
+		Name  string `censor:"display"`
+		Inner inner  `censor:"display"`
+	}
+
+	tests := map[string]struct {
+		cfg     Config
+		value   any
+		expJSON string
+		expText string
+	}{
+		"max slice length": {
+			cfg:     Config{MaxSliceLength: 2},
+			value:   []int{1, 2, 3, 4, 5},
+			expJSON: `[1, 2, "...(+3 more)"]`,
+			expText: `[1, 2, ...(+3 more)]`,
+		},
+		"max slice length with array": {
+			cfg:     Config{MaxSliceLength: 1},
+			value:   [3]string{"a", "b", "c"},
+			expJSON: `["a", "...(+2 more)"]`,
+			expText: `[a, ...(+2 more)]`,
+		},
+		"max slice length is not reached": {
+			cfg:     Config{MaxSliceLength: 3},
+			value:   []int{1, 2, 3},
+			expJSON: `[1, 2, 3]`,
+			expText: `[1, 2, 3]`,
+		},
+		"max string length": {
+			cfg:     Config{MaxStringLength: 5},
+			value:   []string{"short", "long string", "довгий рядок"},
+			expJSON: `["short", "long ...(+6 more)", "\u0434\u043e\u0432\u0433\u0438...(+7 more)"]`,
+			expText: `[short, long ...(+6 more), довги...(+7 more)]`,
+		},
+		"max string length is applied after masking": {
+			cfg:     Config{MaxStringLength: 8, ExcludePatterns: []string{`\w+@\w+\.com`}, MaskValue: "[CENSORED]"},
+			value:   "user@example.com",
+			expJSON: `"[CENSORE...(+2 more)"`,
+			expText: `[CENSORE...(+2 more)`,
+		},
+		"max depth": {
+			cfg:     Config{MaxDepth: 2},
+			value:   payload{Name: "name", Inner: inner{Values: []int{1}}},
+			expJSON: `{"Name": "name","Inner": {"Values": "..."}}`,
+			expText: `{Name: name, Inner: {Values: ...}}`,
+		},
+		"max depth with nested slices": {
+			cfg:     Config{MaxDepth: 1},
+			value:   [][]int{{1}, {2}},
+			expJSON: `["...", "..."]`,
+			expText: `[..., ...]`,
+		},
+		"max output bytes": {
+			cfg:     Config{MaxOutputBytes: 10},
+			value:   []string{"first", "second", "third", "fourth"},
+			expJSON: `["first", "...(+3 more)"]`,
+			expText: `[first, second, ...(+2 more)]`,
+		},
+		"max output bytes with structs": {
+			cfg:     Config{MaxOutputBytes: 5},
+			value:   []payload{{Name: "first"}, {Name: "second"}},
+			expJSON: `[{"Name": "first","...": "(+1 more)"}, "...(+1 more)"]`,
+			expText: `[{Name: first, ...(+1 more)}, ...(+1 more)]`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Run("json", func(t *testing.T) {
+				// GIVEN.
+				e := NewJSONEncoder(tt.cfg)
+				var b bytes.Buffer
+
+				// WHEN.
+				e.Encode(NewBuffer(&b), reflect.ValueOf(tt.value))
+
+				// THEN.
+				require.Equal(t, tt.expJSON, b.String())
+				require.True(t, json.Valid(b.Bytes()))
+			})
+
+			t.Run("text", func(t *testing.T) {
+				// GIVEN.
+				e := NewTextEncoder(tt.cfg)
+				var b bytes.Buffer
+
+				// WHEN.
+				e.Encode(NewBuffer(&b), reflect.ValueOf(tt.value))
+
+				// THEN.
+				require.Equal(t, tt.expText, b.String())
+			})
+		})
+	}
+
+	t.Run("max map entries", func(t *testing.T) {
+		// GIVEN.
+		cfg := Config{MaxMapEntries: 1}
+		value := map[string]int{"a": 1, "b": 2, "c": 3}
+		var jb, tb bytes.Buffer
+
+		// WHEN.
+		NewJSONEncoder(cfg).Encode(NewBuffer(&jb), reflect.ValueOf(value))
+		NewTextEncoder(cfg).Encode(NewBuffer(&tb), reflect.ValueOf(value))
+
+		// THEN.
+		// Map iteration order is random, so only the number of written entries is checked.
+		require.Regexp(t, `^\{"[abc]":[123],"\.\.\.":"\(\+2 more\)"\}$`, jb.String())
+		require.True(t, json.Valid(jb.Bytes()))
+		require.Regexp(t, `^map\[string\]int\{[abc]: [123], \.\.\.\(\+2 more\)\}$`, tb.String())
+	})
+
+	t.Run("max string length is not applied to map keys", func(t *testing.T) {
+		// GIVEN.
+		cfg := Config{MaxStringLength: 5, ExcludePatterns: []string{`\d{4}`}, MaskValue: "[CENSORED]"}
+		value := map[string]string{"customer_email": "value one", "customer_phone_1234": "value two"}
+		var jb, tb bytes.Buffer
+
+		// WHEN.
+		NewJSONEncoder(cfg).Encode(NewBuffer(&jb), reflect.ValueOf(value))
+		NewTextEncoder(cfg).Encode(NewBuffer(&tb), reflect.ValueOf(value))
+
+		// THEN.
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(jb.Bytes(), &got))
+		require.Equal(t, map[string]string{
+			"customer_email":            "value...(+4 more)",
+			"customer_phone_[CENSORED]": "value...(+4 more)",
+		}, got)
+		require.Contains(t, tb.String(), "customer_email: value...(+4 more)")
+		require.Contains(t, tb.String(), "customer_phone_[CENSORED]: value...(+4 more)")
+	})
+
+	t.Run("large slice", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaxOutputBytes: 1024})
+		value := make([]string, 50_000)
+		for i := range value {
+			value[i] = strings.Repeat("x", 16)
+		}
+		var b bytes.Buffer
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		require.Less(t, b.Len(), 1100)
+		require.True(t, json.Valid(b.Bytes()))
+	})
+}
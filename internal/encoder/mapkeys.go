@@ -0,0 +1,89 @@
+package encoder
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// mapKeys matches map keys by regexp patterns.
+type mapKeys struct {
+	pattern *regexp.Regexp
+	// matches caches the results of matching, since the same keys are usually seen over and over again.
+	matches *stringCache[bool]
+}
+
+// newMapKeys compiles the given patterns. Each pattern is grouped, so flags like (?i) apply only to it.
+// The results of matching are cached with the given cache mode and size. It returns nil if there are no patterns.
+func newMapKeys(patterns []string, mode CacheMode, size int) *mapKeys {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	grouped := make([]string, len(patterns))
+	for i, p := range patterns {
+		grouped[i] = "(?:" + p + ")"
+	}
+
+	return &mapKeys{
+		pattern: regexp.MustCompile(strings.Join(grouped, "|")),
+		matches: newStringCache[bool](mode, size),
+	}
+}
+
+// checks reports whether keys of the given type can be matched. Only string keys are matched,
+// including string values of interface keys.
+//
+//nolint:exhaustive
+func (k *mapKeys) checks(t reflect.Type) bool {
+	if k == nil {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// matched reports whether the given map key matches any of the patterns.
+// Keys that contain values of the given secrets registry are not cached, see matchedString.
+func (k *mapKeys) matched(key reflect.Value, secrets *secretRegistry) bool {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+
+	if key.Kind() != reflect.String {
+		return false
+	}
+
+	return k.matchedString(key.String(), secrets)
+}
+
+// matchedString is the same as matched, but for string keys. Keys that contain secrets are not cached,
+// so the secrets are not kept in memory as cache keys.
+func (k *mapKeys) matchedString(s string, secrets *secretRegistry) bool {
+	if matched, ok := k.matches.Get(s); ok {
+		return matched
+	}
+
+	matched := k.pattern.MatchString(s)
+	if matcher := secrets.matcher.Load(); matcher == nil || !matcher.contains(s) {
+		k.matches.Set(s, matched)
+		// The key must be dropped if secrets were registered concurrently, see RegisterSecrets.
+		if secrets.matcher.Load() != matcher {
+			k.matches.Delete(s)
+		}
+	}
+
+	return matched
+}
+
+// reset drops the cached results of matching.
+func (k *mapKeys) reset() {
+	if k != nil {
+		k.matches.Reset()
+	}
+}
@@ -0,0 +1,104 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapKeys_matched(t *testing.T) {
+	keys := newMapKeys([]string{"(?i)pass", "^token$"}, CachePlain, 0)
+
+	tests := map[string]struct {
+		key any
+		exp bool
+	}{
+		"matched":                   {key: "password", exp: true},
+		"case_insensitive":          {key: "PassCode", exp: true},
+		"flags_apply_to_group":      {key: "TOKEN"},
+		"anchored":                  {key: "token", exp: true},
+		"anchored_not_matched":      {key: "access_token"},
+		"not_matched":               {key: "name"},
+		"interface_with_string":     {key: any("password"), exp: true},
+		"interface_with_non_string": {key: any(1)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN.
+			// The key is checked twice to cover the cached result.
+			first := keys.matched(reflect.ValueOf(&tt.key).Elem(), new(secretRegistry))
+			second := keys.matched(reflect.ValueOf(&tt.key).Elem(), new(secretRegistry))
+
+			// THEN.
+			require.Equal(t, tt.exp, first)
+			require.Equal(t, tt.exp, second)
+		})
+	}
+}
+
+func TestMapKeys_matchedString_Secrets(t *testing.T) {
+	// GIVEN.
+	keys := newMapKeys([]string{"(?i)pass"}, CachePlain, 0)
+	var secrets secretRegistry
+	secrets.Register("hunter2")
+
+	// WHEN.
+	matchedSecret := keys.matchedString("password_hunter2", &secrets)
+	matched := keys.matchedString("password", &secrets)
+
+	// THEN.
+	require.True(t, matchedSecret)
+	require.True(t, matched)
+	_, ok := keys.matches.Get("password_hunter2")
+	require.False(t, ok)
+	_, ok = keys.matches.Get("password")
+	require.True(t, ok)
+}
+
+func TestMapKeys_checks(t *testing.T) {
+	keys := newMapKeys([]string{"pass"}, CachePlain, 0)
+
+	require.True(t, keys.checks(reflect.TypeFor[string]()))
+	require.True(t, keys.checks(reflect.TypeFor[any]()))
+	require.False(t, keys.checks(reflect.TypeFor[int]()))
+	require.Nil(t, newMapKeys(nil, CachePlain, 0))
+	require.False(t, newMapKeys(nil, CachePlain, 0).checks(reflect.TypeFor[string]()))
+}
+
+func TestEncoder_MaskMapKeys(t *testing.T) {
+	cfg := Config{MaskValue: "[CENSORED]", MaskMapKeys: []string{"(?i)secret"}}
+	value := []map[string]any{
+		{"Secret": map[string]string{"nested": "value"}},
+		{"name": "Ivan"},
+	}
+
+	tests := map[string]struct {
+		encoder Encoder
+		exp     string
+	}{
+		"json": {
+			encoder: NewJSONEncoder(cfg),
+			exp:     `[{"Secret":"[CENSORED]"}, {"name":"Ivan"}]`,
+		},
+		"text": {
+			encoder: NewTextEncoder(cfg),
+			exp:     `[map[string]interface {}{Secret: [CENSORED]}, map[string]interface {}{name: Ivan}]`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			var b bytes.Buffer
+
+			// WHEN.
+			tt.encoder.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+			// THEN.
+			require.Equal(t, tt.exp, b.String())
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package encoder
+
+import "regexp"
+
+// NamedPattern is a regexp pattern of strings that must be replaced, with an optional replacement of its own.
+type NamedPattern struct {
+	// Name identifies the pattern, e.g. "email".
+	Name string `yaml:"name"`
+	// Pattern is a regexp pattern of the replaced strings.
+	Pattern string `yaml:"pattern"`
+	// Replace is a template of the replacement, e.g. "[EMAIL]" or "$1***@$2". It may refer to the pattern
+	// groups the same way as regexp.Regexp.Expand. An empty template means the default replacement:
+	// the mask value or a digest.
+	Replace string `yaml:"replace"`
+}
+
+// newNamedPatterns returns detectors of the given patterns.
+// Note: this method may panic if any regexp pattern is invalid.
+func newNamedPatterns(patterns []NamedPattern) []*detector {
+	var ds []*detector
+	for _, p := range patterns {
+		ds = append(ds, &detector{
+			name:    p.Name,
+			reason:  ReasonNamedPattern,
+			pattern: regexp.MustCompile(p.Pattern),
+			replace: p.Replace,
+		})
+	}
+
+	return ds
+}
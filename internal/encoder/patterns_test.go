@@ -0,0 +1,95 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_NamedPatterns(t *testing.T) {
+	type payload struct {
+		Note string `censor:"display"`
+	}
+
+	email := NamedPattern{Name: "email", Pattern: `(\w)[\w.]*@([\w.]+)`, Replace: "$1***@$2"}
+
+	tests := map[string]struct {
+		cfg     Config
+		val     string
+		expJSON string
+		expText string
+	}{
+		"capture_groups": {
+			cfg:     Config{NamedPatterns: []NamedPattern{email}},
+			val:     "from ivan.petrov@example.com",
+			expJSON: `{"Note": "from i***@example.com"}`,
+			expText: `{Note: from i***@example.com}`,
+		},
+		"named_groups": {
+			cfg:     Config{NamedPatterns: []NamedPattern{{Name: "card", Pattern: `\d{12}(?P<last4>\d{4})`, Replace: "****${last4}"}}},
+			val:     "card 4111111111111111",
+			expJSON: `{"Note": "card ****1111"}`,
+			expText: `{Note: card ****1111}`,
+		},
+		"label": {
+			cfg:     Config{NamedPatterns: []NamedPattern{{Name: "ssn", Pattern: `\d{3}-\d{2}-\d{4}`, Replace: `"SSN"`}}},
+			val:     "ssn 123-45-6789",
+			expJSON: `{"Note": "ssn \"SSN\""}`,
+			expText: `{Note: ssn "SSN"}`,
+		},
+		"default_replacement": {
+			cfg:     Config{NamedPatterns: []NamedPattern{{Name: "token", Pattern: `tok_\w+`}}},
+			val:     "token tok_123",
+			expJSON: `{"Note": "token [CENSORED]"}`,
+			expText: `{Note: token [CENSORED]}`,
+		},
+		"hashed_default_replacement": {
+			cfg:     Config{NamedPatterns: []NamedPattern{{Name: "token", Pattern: `tok_\w+`}}, HashExcludePatterns: true, HashKey: "key"},
+			val:     "token tok_123",
+			expJSON: `{"Note": "token ` + newHasher("key").Sum("tok_123") + `"}`,
+			expText: `{Note: token ` + newHasher("key").Sum("tok_123") + `}`,
+		},
+		"with_exclude_patterns": {
+			cfg:     Config{NamedPatterns: []NamedPattern{email}, ExcludePatterns: []string{`\d{4}`}},
+			val:     "ivan@example.com 2024",
+			expJSON: `{"Note": "i***@example.com [CENSORED]"}`,
+			expText: `{Note: i***@example.com [CENSORED]}`,
+		},
+		"overlapping_matches_use_default_replacement": {
+			cfg:     Config{NamedPatterns: []NamedPattern{email}, Detectors: []string{"ipv4"}},
+			val:     "ivan@10.0.0.1",
+			expJSON: `{"Note": "[CENSORED]"}`,
+			expText: `{Note: [CENSORED]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.cfg.MaskValue = "[CENSORED]"
+
+			t.Run("json", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				NewJSONEncoder(tt.cfg).Encode(NewBuffer(&b), reflect.ValueOf(payload{Note: tt.val}))
+
+				// THEN.
+				require.Equal(t, tt.expJSON, b.String())
+			})
+
+			t.Run("text", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				NewTextEncoder(tt.cfg).Encode(NewBuffer(&b), reflect.ValueOf(payload{Note: tt.val}))
+
+				// THEN.
+				require.Equal(t, tt.expText, b.String())
+			})
+		})
+	}
+}
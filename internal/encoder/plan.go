@@ -0,0 +1,165 @@
+package encoder
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/vpakhuchyi/censor/internal/cache"
+)
+
+// encoderFunc encodes a value of a specific type.
+type encoderFunc func(b *Buffer, v reflect.Value)
+
+// typePlan contains encoder functions compiled for a specific type.
+// Plans are built once per type and reused, so the type is inspected with reflection only once:
+// struct fields are parsed, element plans are resolved and the encoding rules are selected in advance.
+type typePlan struct {
+	// encode applies all the rules: functions registered with RegisterType, the censor.Censorer interface,
+	// marshalers and kind-specific encoding.
+	encode encoderFunc
+	// kind encodes a value based on its kind only. It's used by the Struct, Slice, Map, Ptr and Interface methods.
+	kind encoderFunc
+}
+
+// planCache stores compiled plans by type.
+// Plans depend on registered functions, so the whole cache is replaced once a new function is registered.
+// The number of cached plans is limited, since types can be created at runtime, e.g. with reflect.StructOf.
+type planCache struct {
+	plans atomic.Pointer[cache.TypeCache[*typePlan]]
+	// building contains placeholders of plans that are being built, see get.
+	building sync.Map
+}
+
+// get returns a plan for the given type. If there is no such plan yet, it's built using the compile function.
+//
+// While a plan is being built, a placeholder is stored for its type, so recursive types refer to
+// the placeholder instead of building their plan endlessly. The placeholder waits until the plan is ready.
+// Placeholders are stored apart from the cached plans, so they are never evicted.
+func (c *planCache) get(t reflect.Type, compile func(t reflect.Type) typePlan) *typePlan {
+	plans := c.plans.Load()
+	if plans == nil {
+		c.plans.CompareAndSwap(nil, cache.NewTypeCache[*typePlan](cache.DefaultMaxCacheSize))
+		plans = c.plans.Load()
+	}
+
+	if plan, ok := plans.Get(t); ok {
+		return plan
+	}
+
+	var (
+		wg    sync.WaitGroup
+		ready *typePlan
+	)
+	wg.Add(1)
+	placeholder := &typePlan{
+		encode: func(b *Buffer, v reflect.Value) {
+			wg.Wait()
+			ready.encode(b, v)
+		},
+		kind: func(b *Buffer, v reflect.Value) {
+			wg.Wait()
+			ready.kind(b, v)
+		},
+	}
+
+	if p, loaded := c.building.LoadOrStore(t, placeholder); loaded {
+		if plan, ok := p.(*typePlan); ok {
+			return plan
+		}
+	}
+
+	p := compile(t)
+	ready = &p
+	wg.Done()
+	plans.Set(t, ready)
+	c.building.Delete(t)
+
+	return ready
+}
+
+// reset drops all compiled plans.
+func (c *planCache) reset() {
+	c.plans.Store(cache.NewTypeCache[*typePlan](cache.DefaultMaxCacheSize))
+}
+
+// withCustomRules wraps the given kind-specific encoder function with the rules that take precedence over it:
+// functions registered with RegisterType and the censor.Censorer interface. Values produced by these rules
+// are encoded with the encode function unless they have the same type as the original value.
+func (e *baseEncoder) withCustomRules(t reflect.Type, next, encode encoderFunc) encoderFunc {
+	withCensorer := next
+	if t.Implements(censorerType) || reflect.PointerTo(t).Implements(censorerType) {
+		withCensorer = func(b *Buffer, v reflect.Value) {
+			cv, ok := censoredValue(v)
+			if !ok {
+				next(b, v)
+
+				return
+			}
+
+			// A value of the same type is encoded as is to avoid infinite recursion.
+			if cv.IsValid() && cv.Type() == t {
+				next(b, cv)
+			} else {
+				encode(b, cv)
+			}
+		}
+	}
+
+	fn, ok := e.types.Get(t)
+	if !ok {
+		return withCensorer
+	}
+
+	return func(b *Buffer, v reflect.Value) {
+		// Nil pointers are never passed to registered functions.
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			withCensorer(b, v)
+
+			return
+		}
+
+		// A registered function may return a value of the same type, e.g. a modified copy of the original value.
+		// In such a case, the value is encoded as is to avoid infinite recursion.
+		rv := reflect.ValueOf(fn(v))
+		if rv.IsValid() && rv.Type() == t {
+			next(b, rv)
+		} else {
+			encode(b, rv)
+		}
+	}
+}
+
+// scalarEncoder returns an encoder function for booleans and numbers, which are written the same way
+// in all the formats. For any other kind, nil is returned.
+//
+//nolint:exhaustive
+func scalarEncoder(k reflect.Kind) encoderFunc {
+	switch k {
+	case reflect.Bool:
+		return func(b *Buffer, v reflect.Value) {
+			b.Write(strconv.AppendBool(b.AvailableBuffer(), v.Bool()))
+		}
+	case reflect.Float32:
+		return func(b *Buffer, v reflect.Value) {
+			b.WriteString(decimal.NewFromFloat32(float32(v.Float())).String())
+		}
+	case reflect.Float64:
+		return func(b *Buffer, v reflect.Value) {
+			b.WriteString(decimal.NewFromFloat(v.Float()).String())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(b *Buffer, v reflect.Value) {
+			b.Write(strconv.AppendInt(b.AvailableBuffer(), v.Int(), 10))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(b *Buffer, v reflect.Value) {
+			b.Write(strconv.AppendUint(b.AvailableBuffer(), v.Uint(), 10))
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,215 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpakhuchyi/censor/internal/cache"
+)
+
+func TestPlanCache_get(t *testing.T) {
+	t.Run("plan is compiled once", func(t *testing.T) {
+		// GIVEN.
+		var c planCache
+		var calls int
+		compile := func(reflect.Type) typePlan {
+			calls++
+
+			return typePlan{}
+		}
+		typ := reflect.TypeFor[string]()
+
+		// WHEN.
+		first := c.get(typ, compile)
+		second := c.get(typ, compile)
+
+		// THEN.
+		require.Same(t, first, second)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("number of plans is limited", func(t *testing.T) {
+		// GIVEN.
+		var c planCache
+		var calls int
+		compile := func(reflect.Type) typePlan {
+			calls++
+
+			return typePlan{}
+		}
+		first := reflect.StructOf([]reflect.StructField{{Name: "F0", Type: reflect.TypeFor[string]()}})
+
+		// WHEN.
+		c.get(first, compile)
+		for i := 1; i <= cache.DefaultMaxCacheSize; i++ {
+			name := "F" + strconv.Itoa(i)
+			c.get(reflect.StructOf([]reflect.StructField{{Name: name, Type: reflect.TypeFor[string]()}}), compile)
+		}
+		c.get(first, compile)
+
+		// THEN.
+		// The oldest plan is evicted, so it's compiled again.
+		require.Equal(t, cache.DefaultMaxCacheSize+2, calls)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		// GIVEN.
+		var c planCache
+		var calls int
+		compile := func(reflect.Type) typePlan {
+			calls++
+
+			return typePlan{}
+		}
+		typ := reflect.TypeFor[string]()
+		c.get(typ, compile)
+
+		// WHEN.
+		c.reset()
+		c.get(typ, compile)
+
+		// THEN.
+		require.Equal(t, 2, calls)
+	})
+}
+
+func TestEncoder_Plans(t *testing.T) {
+	type node struct {
+		Name     string  `censor:"display"`
+		Children []*node `censor:"display"`
+		Next     *node   `censor:"display"`
+	}
+
+	value := &node{
+		Name:     "root",
+		Children: []*node{{Name: "child"}},
+		Next:     &node{Name: "next"},
+	}
+
+	tests := map[string]struct {
+		encoder Encoder
+		exp     string
+	}{
+		"json": {
+			encoder: NewJSONEncoder(Config{MaskValue: "[CENSORED]"}),
+			exp: `{"Name": "root","Children": [{"Name": "child","Children": [],"Next": null}],` +
+				`"Next": {"Name": "next","Children": [],"Next": null}}`,
+		},
+		"text": {
+			encoder: NewTextEncoder(Config{MaskValue: "[CENSORED]"}),
+			exp:     `{Name: root, Children: [{Name: child, Children: [], Next: nil}], Next: {Name: next, Children: [], Next: nil}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Run("recursive type", func(t *testing.T) {
+				// GIVEN.
+				var b bytes.Buffer
+
+				// WHEN.
+				tt.encoder.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+				// THEN.
+				require.Equal(t, tt.exp, b.String())
+			})
+
+			t.Run("concurrent compilation", func(t *testing.T) {
+				// GIVEN.
+				type item struct {
+					Node  node `censor:"display"`
+					Token string
+				}
+				e := tt.encoder
+
+				// WHEN.
+				results := make([]string, 16)
+				var wg sync.WaitGroup
+				for i := range results {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+
+						var b bytes.Buffer
+						e.Encode(NewBuffer(&b), reflect.ValueOf(item{Node: *value, Token: "token"}))
+						results[i] = b.String()
+					}()
+				}
+				wg.Wait()
+
+				// THEN.
+				for _, r := range results {
+					require.Equal(t, results[0], r)
+					require.Contains(t, r, "[CENSORED]")
+				}
+			})
+		})
+	}
+}
+
+func TestEncoder_Plans_RegisterType(t *testing.T) {
+	// GIVEN.
+	type secret struct {
+		Value string `censor:"display"`
+	}
+	type payload struct {
+		Secret secret `censor:"display"`
+	}
+
+	e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+	encode := func() string {
+		var b bytes.Buffer
+		e.Encode(NewBuffer(&b), reflect.ValueOf(payload{Secret: secret{Value: "value"}}))
+
+		return b.String()
+	}
+	require.Equal(t, `{"Secret": {"Value": "value"}}`, encode())
+
+	// WHEN.
+	// The plan of payload is already compiled, so it must be built again to take the new function into account.
+	e.RegisterType(reflect.TypeFor[secret](), func(v reflect.Value) any {
+		return strings.ToUpper(v.Field(0).String())
+	})
+
+	// THEN.
+	require.Equal(t, `{"Secret": "VALUE"}`, encode())
+}
+
+func BenchmarkJSONEncoder_Encode(b *testing.B) {
+	type address struct {
+		City   string `censor:"display"`
+		Street string
+	}
+
+	type user struct {
+		ID        int               `censor:"display"`
+		Email     string            `censor:"display"`
+		Password  string            `censor:"mask"`
+		Addresses []address         `censor:"display"`
+		Meta      map[string]string `censor:"display"`
+	}
+
+	e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+	v := reflect.ValueOf(user{
+		ID:        1,
+		Email:     "user@example.com",
+		Password:  "secret",
+		Addresses: []address{{City: "Kharkiv", Street: "Nauky Avenue"}, {City: "Kyiv", Street: "Khreshchatyk"}},
+		Meta:      map[string]string{"role": "admin"},
+	})
+	buf := NewBuffer(&bytes.Buffer{})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		e.Encode(buf, v)
+	}
+}
@@ -0,0 +1,269 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// errTrailingData is returned if a JSON document contains anything but whitespace after its top-level value.
+var errTrailingData = errors.New("invalid data after top-level value")
+
+// RawJSON encodes the given JSON document. The document is read token by token, so it's never decoded as a whole.
+//
+// Object members are processed the same way as map entries: values of keys matched by the MaskMapKeys patterns
+// are masked, and in the deny-by-default mode, only values of keys matched by the DisplayMapKeys patterns
+// are displayed. Strings are checked against the exclude patterns, and the configured limits are applied.
+// Numbers are written as is and the output is compact.
+//
+// An error is returned if the document is not a single valid JSON value. In such a case, b may contain
+// partial output that must be discarded.
+func (e *JSONEncoder) RawJSON(b *Buffer, raw []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	r := rawJSON{e: e, b: b, dec: dec}
+
+	tok, err := r.token()
+	if err != nil {
+		return err
+	}
+
+	if err = r.value(tok); err != nil {
+		return err
+	}
+
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			return errTrailingData
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// rawJSON holds the state of encoding a single JSON document.
+type rawJSON struct {
+	e   *JSONEncoder
+	b   *Buffer
+	dec *json.Decoder
+}
+
+// token returns the next token. Reaching the end of the input is an error, since it's called only
+// when a token is expected.
+func (r *rawJSON) token() (json.Token, error) {
+	tok, err := r.dec.Token()
+	if errors.Is(err, io.EOF) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return tok, err
+}
+
+// value encodes the value that starts with the given token.
+func (r *rawJSON) value(tok json.Token) error {
+	n := len(r.b.inspection)
+	switch t := tok.(type) {
+	case json.Delim:
+		// The decoder never returns closing delimiters here, since it validates the syntax.
+		if t == '{' {
+			return r.object()
+		}
+
+		return r.array()
+	case string:
+		if !r.masked(t) {
+			r.e.StringValue(r.b, t)
+		}
+	case json.Number:
+		if !r.masked(t) {
+			r.b.WriteString(t.String())
+		}
+	case bool:
+		if !r.masked(t) {
+			r.b.Write(strconv.AppendBool(r.b.AvailableBuffer(), t))
+		}
+	default:
+		r.b.WriteString("null")
+	}
+	r.e.reportDisplayed(r.b, n, reflect.TypeOf(tok))
+
+	return nil
+}
+
+// masked reports whether the given scalar must be masked in the deny-by-default mode. If so, the mask is written.
+func (r *rawJSON) masked(v any) bool {
+	if !r.e.DenyByDefault || r.b.shown {
+		return false
+	}
+
+	r.e.report(r.b, "", ReasonDenyByDefault, "", reflect.TypeOf(v))
+	r.e.writeMask(r.b)
+
+	return true
+}
+
+// object encodes the members of an object whose opening delimiter is already read.
+func (r *rawJSON) object() error {
+	if !r.b.descend(r.e.MaxDepth) {
+		r.b.WriteString(jsonTruncationMarker)
+
+		return r.skipFrom(1)
+	}
+	defer r.b.ascend()
+
+	r.b.WriteByte('{')
+	for i := 0; r.dec.More(); i++ {
+		if i != 0 {
+			r.b.WriteByte(',')
+		}
+
+		if r.e.truncate(r.b, i, r.e.MaxMapEntries) {
+			n, err := r.skipRemaining(true)
+			if err != nil {
+				return err
+			}
+
+			r.b.WriteString(`"` + truncationMarker + `":"`)
+			r.b.WriteString(moreCount(n))
+			r.b.WriteByte('"')
+
+			break
+		}
+
+		r.b.flush()
+		if err := r.member(); err != nil {
+			return err
+		}
+	}
+	r.b.WriteByte('}')
+
+	return r.end()
+}
+
+// member encodes a single member of an object.
+func (r *rawJSON) member() error {
+	tok, err := r.token()
+	if err != nil {
+		return err
+	}
+
+	// The decoder guarantees that object keys are strings. They are never truncated, see JSONEncoder.encodeMapKey.
+	key, _ := tok.(string)
+	r.e.StringEscaped(r.b, key)
+	r.b.WriteByte(':')
+
+	if reason := r.e.maskMember(key); reason != "" {
+		if r.e.audited {
+			r.e.report(r.b, "."+key, reason, "", nil)
+		}
+		r.e.writeMask(r.b)
+
+		return r.skipFrom(0)
+	}
+
+	if tok, err = r.token(); err != nil {
+		return err
+	}
+
+	shown, n := r.b.show(), r.e.pushKey(r.b, key)
+	err = r.value(tok)
+	r.b.shown = shown
+	r.b.popPath(n)
+
+	return err
+}
+
+// array encodes the elements of an array whose opening delimiter is already read.
+func (r *rawJSON) array() error {
+	if !r.b.descend(r.e.MaxDepth) {
+		r.b.WriteString(jsonTruncationMarker)
+
+		return r.skipFrom(1)
+	}
+	defer r.b.ascend()
+
+	r.b.WriteByte('[')
+	for i := 0; r.dec.More(); i++ {
+		if i != 0 {
+			r.b.WriteByte(',')
+		}
+
+		if r.e.truncate(r.b, i, r.e.MaxSliceLength) {
+			n, err := r.skipRemaining(false)
+			if err != nil {
+				return err
+			}
+
+			r.b.WriteString(`"` + moreMarker(n) + `"`)
+
+			break
+		}
+
+		r.b.flush()
+		tok, err := r.token()
+		if err != nil {
+			return err
+		}
+
+		n := r.e.pushPath(r.b, "[]")
+		if err = r.value(tok); err != nil {
+			return err
+		}
+		r.b.popPath(n)
+	}
+	r.b.WriteByte(']')
+
+	return r.end()
+}
+
+// end reads the closing delimiter of an object or array.
+func (r *rawJSON) end() error {
+	_, err := r.token()
+
+	return err
+}
+
+// skipRemaining reads the remaining members of an object or elements of an array and returns their number.
+func (r *rawJSON) skipRemaining(object bool) (int, error) {
+	var n int
+	for ; r.dec.More(); n++ {
+		if object {
+			if _, err := r.token(); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := r.skipFrom(0); err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// skipFrom reads tokens without encoding them until the given number of nested objects and arrays are closed.
+// With zero depth, it reads a single value.
+func (r *rawJSON) skipFrom(depth int) error {
+	for {
+		tok, err := r.token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONEncoder_RawJSON(t *testing.T) {
+	tests := map[string]struct {
+		cfg    Config
+		raw    string
+		exp    string
+		expErr string
+	}{
+		"scalars": {
+			raw: ` {"s": "text", "n": 1.50, "big": 12345678901234567890, "b": true, "null": null} `,
+			exp: `{"s":"text","n":1.50,"big":12345678901234567890,"b":true,"null":null}`,
+		},
+		"nested": {
+			raw: `[{"a": [1, 2, {"b": []}]}, {}]`,
+			exp: `[{"a":[1,2,{"b":[]}]},{}]`,
+		},
+		"top_level_string": {
+			raw: `"user@example.com"`,
+			exp: `"[CENSORED]"`,
+			cfg: Config{ExcludePatterns: []string{`\w+@\w+\.com`}},
+		},
+		"exclude_patterns": {
+			raw: `{"contacts": ["mail me at user@example.com"], "user@example.com": 1}`,
+			exp: `{"contacts":["mail me at [CENSORED]"],"[CENSORED]":1}`,
+			cfg: Config{ExcludePatterns: []string{`\w+@\w+\.com`}},
+		},
+		"escaping": {
+			raw: `{"quote": "say \"hi\"\n", "unicode": "é"}`,
+			exp: `{"quote":"say \"hi\"\n","unicode":"\u00e9"}`,
+		},
+		"mask_map_keys": {
+			raw: `{"user": {"name": "Ivan", "password": {"old": "a", "new": ["b"]}}, "tokens": [1, 2]}`,
+			exp: `{"user":{"name":"Ivan","password":"[CENSORED]"},"tokens":"[CENSORED]"}`,
+			cfg: Config{MaskMapKeys: []string{"(?i)pass", "token"}},
+		},
+		"deny_by_default": {
+			raw: `{"id": 1, "items": [{"id": 2, "price": 3}], "name": "Ivan", "tags": ["a"], "ok": false}`,
+			exp: `{"id":1,"items":[{"id":2,"price":"[CENSORED]"}],"name":"[CENSORED]","tags":"[CENSORED]","ok":"[CENSORED]"}`,
+			cfg: Config{DenyByDefault: true, DisplayMapKeys: []string{"^id$", "^items$"}},
+		},
+		"deny_by_default_top_level": {
+			raw: `["a", 1, null]`,
+			exp: `["[CENSORED]","[CENSORED]",null]`,
+			cfg: Config{DenyByDefault: true},
+		},
+		"max_depth": {
+			raw: `{"a": {"b": [1]}, "c": [[1], 2]}`,
+			exp: `{"a":{"b":"..."},"c":["...",2]}`,
+			cfg: Config{MaxDepth: 2},
+		},
+		"collection_limits": {
+			raw: `{"a": [1, 2, [3, 4], {"x": 5}], "b": 1, "c": {"d": [1]}}`,
+			exp: `{"a":[1,2,"...(+2 more)"],"...":"(+2 more)"}`,
+			cfg: Config{MaxSliceLength: 2, MaxMapEntries: 1},
+		},
+		"max_string_length": {
+			raw: `{"key": "abcdef"}`,
+			exp: `{"key":"abc...(+3 more)"}`,
+			cfg: Config{MaxStringLength: 3},
+		},
+		"max_string_length_long_keys": {
+			raw: `{"customer_email": "abcdef", "customer_phone": 1}`,
+			exp: `{"customer_email":"abc...(+3 more)","customer_phone":1}`,
+			cfg: Config{MaxStringLength: 3},
+		},
+		"empty": {
+			raw:    ` `,
+			expErr: "unexpected EOF",
+		},
+		"unexpected_end": {
+			raw:    `{"a": [1, `,
+			expErr: "unexpected end of JSON input",
+		},
+		"unexpected_end_in_masked_value": {
+			raw:    `{"password": {"a": `,
+			expErr: "unexpected EOF",
+			cfg:    Config{MaskMapKeys: []string{"password"}},
+		},
+		"trailing_data": {
+			raw:    `{} {}`,
+			expErr: errTrailingData.Error(),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			tt.cfg.MaskValue = "[CENSORED]"
+			e := NewJSONEncoder(tt.cfg)
+			var b bytes.Buffer
+
+			// WHEN.
+			err := e.RawJSON(NewBuffer(&b), []byte(tt.raw))
+
+			// THEN.
+			if tt.expErr != "" {
+				require.ErrorContains(t, err, tt.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.exp, b.String())
+			require.True(t, json.Valid(b.Bytes()))
+		})
+	}
+
+	t.Run("syntax_error", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+
+		for _, raw := range []string{`{"a" 1}`, `[1,]`, `{"a": 1} x`, `{1: 2}`, `tru`} {
+			// WHEN.
+			err := e.RawJSON(NewBuffer(&bytes.Buffer{}), []byte(raw))
+
+			// THEN.
+			require.Error(t, err, raw)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package encoder
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// TypeFunc converts a value of a registered type to a value that is encoded instead of it.
+type TypeFunc func(v reflect.Value) any
+
+// typeRegistry stores functions registered for specific types.
+// Reads are lock-free as the registry is checked for every encoded value,
+// while writes copy the whole map, as registration usually happens only on the initialization stage.
+type typeRegistry struct {
+	mu    sync.Mutex
+	funcs atomic.Pointer[map[reflect.Type]TypeFunc]
+	// predeclared is set once a function is registered for a predeclared type, e.g. string or int.
+	predeclared atomic.Bool
+}
+
+// Register stores the given function for the given type. An existing function for the same type is replaced.
+func (r *typeRegistry) Register(t reflect.Type, fn TypeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	funcs := make(map[reflect.Type]TypeFunc)
+	if current := r.funcs.Load(); current != nil {
+		maps.Copy(funcs, *current)
+	}
+	funcs[t] = fn
+
+	r.funcs.Store(&funcs)
+	if t.PkgPath() == "" && t.Name() != "" {
+		r.predeclared.Store(true)
+	}
+}
+
+// Get returns a function registered for the given type.
+func (r *typeRegistry) Get(t reflect.Type) (TypeFunc, bool) {
+	funcs := r.funcs.Load()
+	if funcs == nil {
+		return nil, false
+	}
+
+	fn, ok := (*funcs)[t]
+
+	return fn, ok
+}
+
+// All returns a copy of all registered functions.
+func (r *typeRegistry) All() map[reflect.Type]TypeFunc {
+	funcs := r.funcs.Load()
+	if funcs == nil {
+		return nil
+	}
+
+	return maps.Clone(*funcs)
+}
+
+// RegisterType registers a function that converts values of the given type before they are encoded.
+// The returned value is encoded using the regular rules, so it's still a subject for masking.
+func (e *baseEncoder) RegisterType(t reflect.Type, fn TypeFunc) {
+	e.types.Register(t, fn)
+	// Compiled plans may refer to the previous set of functions, so they are built again.
+	e.plans.reset()
+}
+
+// HasPredeclaredTypes reports whether a function is registered for any predeclared type, e.g. string or int.
+func (e *baseEncoder) HasPredeclaredTypes() bool {
+	return e.types.predeclared.Load()
+}
+
+// RegisteredTypes returns a copy of all functions registered with RegisterType.
+func (e *baseEncoder) RegisteredTypes() map[reflect.Type]TypeFunc {
+	return e.types.All()
+}
@@ -0,0 +1,125 @@
+package encoder
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeRegistry(t *testing.T) {
+	t.Run("empty registry", func(t *testing.T) {
+		// GIVEN.
+		var r typeRegistry
+
+		// WHEN.
+		fn, ok := r.Get(reflect.TypeFor[string]())
+
+		// THEN.
+		require.False(t, ok)
+		require.Nil(t, fn)
+		require.Nil(t, r.All())
+	})
+
+	t.Run("register and replace", func(t *testing.T) {
+		// GIVEN.
+		var r typeRegistry
+		typ := reflect.TypeFor[string]()
+
+		// WHEN.
+		r.Register(typ, func(reflect.Value) any { return 1 })
+		r.Register(typ, func(reflect.Value) any { return 2 })
+
+		// THEN.
+		fn, ok := r.Get(typ)
+		require.True(t, ok)
+		require.Equal(t, 2, fn(reflect.Value{}))
+		require.Len(t, r.All(), 1)
+	})
+}
+
+func TestEncoder_RegisterType(t *testing.T) {
+	type user struct {
+		IP       net.IP `censor:"display"`
+		Name     string `censor:"display"`
+		Password string
+	}
+
+	// hideLastOctet keeps only the network part of an IPv4 address.
+	hideLastOctet := func(v reflect.Value) any {
+		s := v.Interface().(net.IP).String()
+
+		return s[:strings.LastIndexByte(s, '.')] + ".x"
+	}
+
+	// upper returns a value of the same type to check that there is no infinite recursion.
+	upper := func(v reflect.Value) any {
+		return strings.ToUpper(v.String())
+	}
+
+	value := &user{IP: net.IPv4(192, 168, 0, 1), Name: "ivan", Password: "secret"}
+
+	t.Run("json", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]", ExcludePatterns: []string{`IVAN`}})
+		e.RegisterType(reflect.TypeFor[net.IP](), hideLastOctet)
+		e.RegisterType(reflect.TypeFor[string](), upper)
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		// The value returned for net.IP is a string, so the function registered for strings is applied to it too.
+		require.Equal(t, `{"IP": "192.168.0.X","Name": "[CENSORED]","Password": "[CENSORED]"}`, b.String())
+		require.Len(t, e.RegisteredTypes(), 2)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		// GIVEN.
+		e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+		e.RegisterType(reflect.TypeFor[net.IP](), hideLastOctet)
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		require.Equal(t, `{IP: 192.168.0.x, Name: ivan, Password: [CENSORED]}`, b.String())
+	})
+
+	t.Run("nil pointer is not passed to the function", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		e.RegisterType(reflect.TypeFor[*user](), func(v reflect.Value) any {
+			return v.Elem().FieldByName("Name").String()
+		})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf([]*user{nil, value}))
+
+		// THEN.
+		require.Equal(t, `[null, "ivan"]`, b.String())
+	})
+
+	t.Run("function returns nil", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		e.RegisterType(reflect.TypeFor[net.IP](), func(reflect.Value) any { return nil })
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(net.IPv4(127, 0, 0, 1)))
+
+		// THEN.
+		require.Equal(t, `null`, b.String())
+	})
+}
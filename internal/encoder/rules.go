@@ -0,0 +1,103 @@
+package encoder
+
+import (
+	"reflect"
+	"strings"
+)
+
+// anyType is used in field rules to match fields of any struct type, e.g. "*.Password".
+const anyType = "*"
+
+// fieldRuleKey identifies a struct field in field rules.
+type fieldRuleKey struct {
+	// typ is a full type name including the package path, e.g. "github.com/acme/api.User", or anyType.
+	typ   string
+	field string
+}
+
+// fieldRule is an action configured for a struct field.
+type fieldRule struct {
+	action Action
+	keep   int
+}
+
+// fieldRules contains actions configured for struct fields by dotted paths.
+// They take precedence over struct tags, which makes it possible to change the masking of fields
+// without changing the code, including fields of third-party types.
+type fieldRules map[fieldRuleKey]fieldRule
+
+// newFieldRules parses the given rules. Keys are dotted paths like "github.com/acme/api.User.Email"
+// or "*.Password", values are censor tag values. Invalid rules are skipped, they must be validated in advance.
+func newFieldRules(rules map[string]string) fieldRules {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	parsed := make(fieldRules, len(rules))
+	for path, value := range rules {
+		key, ok := parseFieldRulePath(path)
+		if !ok {
+			continue
+		}
+
+		action, keep, ok := parseTag(value)
+		if !ok {
+			continue
+		}
+
+		parsed[key] = fieldRule{action: action, keep: keep}
+	}
+
+	return parsed
+}
+
+// ValidFieldRulePath reports whether the given field rule path is valid.
+func ValidFieldRulePath(path string) bool {
+	_, ok := parseFieldRulePath(path)
+
+	return ok
+}
+
+// parseFieldRulePath splits a field rule path into the type and field names.
+// The type name must be either "*" or a type name qualified with the package path.
+func parseFieldRulePath(path string) (fieldRuleKey, bool) {
+	i := strings.LastIndexByte(path, '.')
+	if i <= 0 || i == len(path)-1 {
+		return fieldRuleKey{}, false
+	}
+
+	typ, field := path[:i], path[i+1:]
+	if typ != anyType && !strings.Contains(typ, ".") {
+		return fieldRuleKey{}, false
+	}
+
+	return fieldRuleKey{typ: typ, field: field}, true
+}
+
+// get returns a rule for the given field of the given type. Rules for the specific type take precedence
+// over rules for any type.
+func (r fieldRules) get(typeName, field string) (fieldRule, bool) {
+	if len(r) == 0 {
+		return fieldRule{}, false
+	}
+
+	if typeName != "" {
+		if rule, ok := r[fieldRuleKey{typ: typeName, field: field}]; ok {
+			return rule, true
+		}
+	}
+
+	rule, ok := r[fieldRuleKey{typ: anyType, field: field}]
+
+	return rule, ok
+}
+
+// TypeName returns the name of the given type qualified with its package path, which is used in field rules.
+// An empty string is returned for unnamed types.
+func TypeName(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+
+	return t.PkgPath() + "." + t.Name()
+}
@@ -0,0 +1,81 @@
+package encoder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldRules_get(t *testing.T) {
+	rules := newFieldRules(map[string]string{
+		"github.com/acme/api.User.Email": "display",
+		"github.com/acme/api.User.Card":  "mask=last4",
+		"*.Email":                        "omit",
+		"*.Password":                     "hash",
+		"api.User.Token":                 "display",
+		"*.Invalid":                      "show",
+		"User.Name":                      "display",
+	})
+
+	tests := map[string]struct {
+		typeName string
+		field    string
+		exp      fieldRule
+		expOK    bool
+	}{
+		"exact":                 {typeName: "github.com/acme/api.User", field: "Email", exp: fieldRule{action: ActionDisplay}, expOK: true},
+		"exact_with_keep":       {typeName: "github.com/acme/api.User", field: "Card", exp: fieldRule{action: ActionMaskLast, keep: 4}, expOK: true},
+		"any_type":              {typeName: "github.com/acme/api.Account", field: "Email", exp: fieldRule{action: ActionOmit}, expOK: true},
+		"any_type_for_unnamed":  {typeName: "", field: "Password", exp: fieldRule{action: ActionHash}, expOK: true},
+		"short_package_path":    {typeName: "api.User", field: "Token", exp: fieldRule{action: ActionDisplay}, expOK: true},
+		"no_rule":               {typeName: "github.com/acme/api.User", field: "ID"},
+		"invalid_action":        {typeName: "github.com/acme/api.User", field: "Invalid"},
+		"path_without_package":  {typeName: "User", field: "Name"},
+		"field_name_is_matched": {typeName: "github.com/acme/api.User", field: "email"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// WHEN.
+			got, ok := rules.get(tt.typeName, tt.field)
+
+			// THEN.
+			require.Equal(t, tt.expOK, ok)
+			require.Equal(t, tt.exp, got)
+		})
+	}
+
+	t.Run("no_rules", func(t *testing.T) {
+		require.Nil(t, newFieldRules(nil))
+
+		_, ok := newFieldRules(nil).get("github.com/acme/api.User", "Email")
+		require.False(t, ok)
+	})
+}
+
+func TestValidFieldRulePath(t *testing.T) {
+	tests := map[string]bool{
+		"github.com/acme/api.User.Email": true,
+		"main.User.Email":                true,
+		"*.Email":                        true,
+		"User.Email":                     false,
+		"Email":                          false,
+		"*.":                             false,
+		".Email":                         false,
+		"":                               false,
+	}
+
+	for path, exp := range tests {
+		t.Run(path, func(t *testing.T) {
+			require.Equal(t, exp, ValidFieldRulePath(path))
+		})
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	type user struct{}
+
+	require.Equal(t, "github.com/vpakhuchyi/censor/internal/encoder.user", TypeName(reflect.TypeFor[user]()))
+	require.Empty(t, TypeName(reflect.TypeFor[struct{ Name string }]()))
+}
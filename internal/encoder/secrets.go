@@ -0,0 +1,193 @@
+package encoder
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// secretMatcher finds exact occurrences of secret literals in strings with the Aho–Corasick algorithm,
+// so strings are scanned once regardless of the number of secrets.
+type secretMatcher struct {
+	// children contains the transitions of the trie by node. The root node has index zero.
+	children []map[byte]int32
+	// fail contains the node of the longest proper suffix of the node's path that is also a path in the trie.
+	fail []int32
+	// length contains the length of the longest secret that ends at the node, including the ones that end
+	// at the nodes of its suffixes, or zero.
+	length []int
+}
+
+// newSecretMatcher returns a matcher of the given non-empty secrets.
+func newSecretMatcher(secrets []string) *secretMatcher {
+	m := &secretMatcher{
+		children: []map[byte]int32{{}},
+		fail:     []int32{0},
+		length:   []int{0},
+	}
+
+	for _, s := range secrets {
+		var node int32
+		for i := 0; i < len(s); i++ {
+			next, ok := m.children[node][s[i]]
+			if !ok {
+				next = int32(len(m.children)) //nolint:gosec
+				m.children = append(m.children, map[byte]int32{})
+				m.fail = append(m.fail, 0)
+				m.length = append(m.length, 0)
+				m.children[node][s[i]] = next
+			}
+			node = next
+		}
+		m.length[node] = len(s)
+	}
+
+	// Failure links are built in the breadth-first order, so links of shorter paths are ready first.
+	queue := make([]int32, 0, len(m.children))
+	for _, child := range m.children[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for c, child := range m.children[node] {
+			m.fail[child] = m.next(m.fail[node], c)
+			m.length[child] = max(m.length[child], m.length[m.fail[child]])
+			queue = append(queue, child)
+		}
+	}
+
+	return m
+}
+
+// next returns the node that follows the given one after the given byte.
+func (m *secretMatcher) next(node int32, c byte) int32 {
+	for {
+		if next, ok := m.children[node][c]; ok {
+			return next
+		}
+		if node == 0 {
+			return 0
+		}
+		node = m.fail[node]
+	}
+}
+
+// appendMatches appends the ranges of secrets found in s to matches. Overlapping secrets are joined.
+func (m *secretMatcher) appendMatches(matches []match, s string) []match {
+	first := len(matches)
+
+	var node int32
+	for i := 0; i < len(s); i++ {
+		node = m.next(node, s[i])
+
+		n := m.length[node]
+		if n == 0 {
+			continue
+		}
+
+		start, end := i+1-n, i+1
+		if last := len(matches) - 1; last >= first && start <= matches[last].end {
+			matches[last].start = min(matches[last].start, start)
+			matches[last].end = end
+
+			continue
+		}
+		matches = append(matches, match{start: start, end: end, secret: true})
+	}
+
+	return matches
+}
+
+// contains reports whether s contains any of the secrets.
+func (m *secretMatcher) contains(s string) bool {
+	var node int32
+	for i := 0; i < len(s); i++ {
+		node = m.next(node, s[i])
+		if m.length[node] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secretRegistry stores literal values that are masked in all strings.
+// Reads are lock-free as the matcher is used for every string, while writes rebuild the whole matcher,
+// as registration usually happens only on the initialization stage.
+type secretRegistry struct {
+	mu      sync.Mutex
+	values  []string
+	matcher atomic.Pointer[secretMatcher]
+}
+
+// Register adds the given values to the registry. Empty and already registered values are ignored.
+// It reports whether any value was added.
+func (r *secretRegistry) Register(values ...string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := false
+	for _, v := range values {
+		if v != "" && !slices.Contains(r.values, v) {
+			r.values = append(r.values, v)
+			added = true
+		}
+	}
+
+	if added {
+		r.matcher.Store(newSecretMatcher(r.values))
+	}
+
+	return added
+}
+
+// All returns a copy of all registered values.
+func (r *secretRegistry) All() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.values)
+}
+
+// RegisterSecrets registers literal values that are masked in every encoded string, regardless of tags,
+// patterns and detectors. Strings that contain them are never used as cache keys.
+// It's safe to call RegisterSecrets concurrently with encoding.
+func (e *baseEncoder) RegisterSecrets(values ...string) {
+	if !e.secrets.Register(values...) {
+		return
+	}
+
+	// Cached results may have been computed before the values were registered, so they are dropped.
+	// The matcher is stored first, so results that are computed concurrently are dropped too, see cacheString.
+	e.regexpCache.Reset()
+	e.escapedStringsCache.Reset()
+	e.maskMapKeys.reset()
+	e.displayMapKeys.reset()
+}
+
+// RegisteredSecrets returns a copy of all values registered with RegisterSecrets.
+func (e *baseEncoder) RegisteredSecrets() []string {
+	return e.secrets.All()
+}
+
+// cacheString stores the given key and value in the cache if the key was checked against
+// the current set of secrets, see RegisterSecrets.
+func (e *baseEncoder) cacheString(c *stringCache[string], key, value string, secrets *secretMatcher) {
+	c.Set(key, value)
+	if e.secrets.matcher.Load() != secrets {
+		c.Delete(key)
+	}
+}
+
+// containsSecret reports whether any of the given matches contains a registered secret.
+func containsSecret(matches []match) bool {
+	for _, m := range matches {
+		if m.secret {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,280 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretMatcher(t *testing.T) {
+	tests := map[string]struct {
+		secrets []string
+		val     string
+		exp     []match
+	}{
+		"no_matches": {
+			secrets: []string{"hunter2"},
+			val:     "hunter hunter3",
+		},
+		"several_occurrences": {
+			secrets: []string{"abc"},
+			val:     "abc-xabcx",
+			exp:     []match{{start: 0, end: 3, secret: true}, {start: 5, end: 8, secret: true}},
+		},
+		"secret_within_another_one": {
+			secrets: []string{"bc", "abcd"},
+			val:     "abcd bc",
+			exp:     []match{{start: 0, end: 4, secret: true}, {start: 5, end: 7, secret: true}},
+		},
+		"overlapping_secrets_are_joined": {
+			secrets: []string{"abc", "cde"},
+			val:     "xabcdex",
+			exp:     []match{{start: 1, end: 6, secret: true}},
+		},
+		"adjacent_secrets_are_joined": {
+			secrets: []string{"ab", "cd"},
+			val:     "abcd",
+			exp:     []match{{start: 0, end: 4, secret: true}},
+		},
+		"failure_links": {
+			secrets: []string{"aab", "ab"},
+			val:     "aaab",
+			exp:     []match{{start: 1, end: 4, secret: true}},
+		},
+		"suffix_of_another_secret": {
+			secrets: []string{"shehers", "he", "hers"},
+			val:     "ushers",
+			exp:     []match{{start: 2, end: 6, secret: true}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			m := newSecretMatcher(tt.secrets)
+
+			// WHEN.
+			got := m.appendMatches(nil, tt.val)
+
+			// THEN.
+			require.Equal(t, tt.exp, got)
+		})
+	}
+}
+
+func TestEncoder_RegisterSecrets(t *testing.T) {
+	type payload struct {
+		Note    string          `censor:"display"`
+		Payload json.RawMessage `censor:"display"`
+	}
+
+	val := payload{Note: "password is hunter2!", Payload: json.RawMessage(`{"token": "t0ken-hunter2"}`)}
+
+	t.Run("json", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+		e.RegisterSecrets("hunter2", "", "hunter2")
+		var b bytes.Buffer
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(val))
+
+		// THEN.
+		require.Equal(t, `{"Note": "password is [CENSORED]!","Payload": {"token":"t0ken-[CENSORED]"}}`, b.String())
+		require.Equal(t, []string{"hunter2"}, e.RegisteredSecrets())
+	})
+
+	t.Run("text", func(t *testing.T) {
+		// GIVEN.
+		e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+		e.RegisterSecrets("hunter2")
+		var b bytes.Buffer
+
+		// WHEN.
+		e.Encode(NewBuffer(&b), reflect.ValueOf(val))
+
+		// THEN.
+		require.Equal(t, `{Note: password is [CENSORED]!, Payload: {"token":"t0ken-[CENSORED]"}}`, b.String())
+	})
+
+	t.Run("with_patterns", func(t *testing.T) {
+		// GIVEN.
+		e := NewJSONEncoder(Config{
+			MaskValue:     "[CENSORED]",
+			NamedPatterns: []NamedPattern{{Name: "user", Pattern: `user=(\w+)`, Replace: "user=$1"}},
+		})
+		e.RegisterSecrets("hunter2")
+		var b bytes.Buffer
+
+		// WHEN.
+		e.String(NewBuffer(&b), "user=hunter2 user=ivan")
+
+		// THEN.
+		require.Equal(t, `[CENSORED] user=ivan`, b.String())
+	})
+}
+
+func TestEncoder_RegisterSecrets_Cache(t *testing.T) {
+	for name, e := range map[string]interface {
+		Encoder
+		cached(s string) bool
+	}{
+		"json": jsonCacheEncoder{NewJSONEncoder(Config{MaskValue: "[CENSORED]", ExcludePatterns: []string{`\d+`}})},
+		"text": textCacheEncoder{NewTextEncoder(Config{MaskValue: "[CENSORED]", ExcludePatterns: []string{`\d+`}})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			var b bytes.Buffer
+			e.String(NewBuffer(&b), "key hunter2")
+			require.True(t, e.cached("key hunter2"))
+
+			// WHEN.
+			e.RegisterSecrets("hunter2")
+			b.Reset()
+			e.String(NewBuffer(&b), "key hunter2")
+
+			// THEN.
+			require.Contains(t, b.String(), "key [CENSORED]")
+			require.False(t, e.cached("key hunter2"))
+		})
+	}
+}
+
+func TestEncoder_RegisterSecrets_MapKeys(t *testing.T) {
+	// GIVEN.
+	e := NewJSONEncoder(Config{
+		MaskValue:      "[CENSORED]",
+		MaskMapKeys:    []string{"(?i)pass"},
+		DenyByDefault:  true,
+		DisplayMapKeys: []string{"^id"},
+	})
+	var b bytes.Buffer
+	e.Encode(NewBuffer(&b), reflect.ValueOf(map[string]string{"password": "a", "id_hunter2": "b"}))
+	_, masked := e.maskMapKeys.matches.Get("password")
+	_, displayed := e.displayMapKeys.matches.Get("id_hunter2")
+	require.True(t, masked)
+	require.True(t, displayed)
+
+	// WHEN.
+	e.RegisterSecrets("hunter2")
+	b.Reset()
+	e.Encode(NewBuffer(&b), reflect.ValueOf(map[string]string{"id_hunter2": "b"}))
+
+	// THEN.
+	require.Equal(t, `{"id_[CENSORED]":"b"}`, b.String())
+	_, masked = e.maskMapKeys.matches.Get("password")
+	_, displayed = e.displayMapKeys.matches.Get("id_hunter2")
+	require.False(t, masked)
+	require.False(t, displayed)
+}
+
+type secretJSONMarshaler struct{}
+
+func (secretJSONMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"s": "key hunter2", "n": 1, "e": "a\"hunter2"}`), nil
+}
+
+type secretTextMarshaler struct{}
+
+func (secretTextMarshaler) MarshalText() ([]byte, error) {
+	return []byte("key hunter2"), nil
+}
+
+func TestEncoder_RegisterSecrets_Marshalers(t *testing.T) {
+	type payload struct {
+		JSON secretJSONMarshaler `censor:"display"`
+		Text secretTextMarshaler `censor:"display"`
+	}
+
+	tests := map[string]struct {
+		encoder Encoder
+		val     any
+		exp     string
+	}{
+		"json_marshaler": {
+			encoder: NewJSONEncoder(Config{MaskValue: "[CENSORED]"}),
+			val:     payload{},
+			exp:     `{"JSON": {"s": "key [CENSORED]", "n": 1, "e": "a\"[CENSORED]"},"Text": {}}`,
+		},
+		"text_marshaler": {
+			encoder: NewTextEncoder(Config{MaskValue: "[CENSORED]"}),
+			val:     payload{},
+			exp:     `{JSON: {}, Text: key [CENSORED]}`,
+		},
+		"json_map_key": {
+			encoder: NewJSONEncoder(Config{MaskValue: "[CENSORED]"}),
+			val:     map[secretTextMarshaler]int{{}: 1},
+			exp:     `{key [CENSORED]:1}`,
+		},
+		"text_map_key": {
+			encoder: NewTextEncoder(Config{MaskValue: "[CENSORED]"}),
+			val:     map[secretTextMarshaler]int{{}: 1},
+			exp:     `map[encoder.secretTextMarshaler]int{key [CENSORED]: 1}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN.
+			tt.encoder.RegisterSecrets("hunter2")
+			var b bytes.Buffer
+
+			// WHEN.
+			tt.encoder.Encode(NewBuffer(&b), reflect.ValueOf(tt.val))
+
+			// THEN.
+			require.Equal(t, tt.exp, b.String())
+		})
+	}
+}
+
+type jsonCacheEncoder struct{ *JSONEncoder }
+
+func (e jsonCacheEncoder) cached(s string) bool {
+	_, inRegexpCache := e.regexpCache.Get(s)
+	_, inEscapedCache := e.escapedStringsCache.Get(s)
+
+	return inRegexpCache || inEscapedCache
+}
+
+type textCacheEncoder struct{ *TextEncoder }
+
+func (e textCacheEncoder) cached(s string) bool {
+	_, ok := e.regexpCache.Get(s)
+
+	return ok
+}
+
+func TestEncoder_RegisterSecrets_Concurrent(t *testing.T) {
+	// GIVEN.
+	e := NewJSONEncoder(Config{MaskValue: "[CENSORED]"})
+	secrets := []string{"s3cret-0", "s3cret-1", "s3cret-2", "s3cret-3"}
+
+	// WHEN.
+	var wg sync.WaitGroup
+	for _, s := range secrets {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			e.RegisterSecrets(s)
+		}()
+		go func() {
+			defer wg.Done()
+			var b bytes.Buffer
+			e.String(NewBuffer(&b), "value "+s)
+		}()
+	}
+	wg.Wait()
+
+	// THEN.
+	for _, s := range secrets {
+		var b bytes.Buffer
+		e.String(NewBuffer(&b), "value "+s)
+		require.Equal(t, `value [CENSORED]`, b.String())
+	}
+	require.ElementsMatch(t, secrets, e.RegisteredSecrets())
+}
@@ -0,0 +1,91 @@
+package encoder
+
+import (
+	"crypto/rand"
+
+	"github.com/vpakhuchyi/censor/internal/cache"
+)
+
+// CacheMode describes how encoded strings are used as keys of the internal caches.
+type CacheMode uint8
+
+const (
+	// CachePlain uses strings as cache keys as is. It's the default mode.
+	CachePlain CacheMode = iota
+	// CacheHashed uses keyed HMAC-SHA256 digests of strings as cache keys, so the original strings
+	// are not kept in memory. The key is random and is generated for every cache.
+	CacheHashed
+	// CacheDisabled disables the caches of strings.
+	CacheDisabled
+)
+
+// stringCache is a size-limited cache of values by strings. Depending on the mode, the strings are used
+// as keys as is or replaced with keyed digests. A nil cache never stores anything.
+type stringCache[T comparable] struct {
+	cache *cache.Cache[T]
+	// hasher computes digests of keys. It's nil if the keys are used as is.
+	hasher *hasher
+}
+
+// newStringCache returns a cache with the given mode and maximum size.
+// Zero size means cache.DefaultMaxCacheSize. It returns nil if the mode is CacheDisabled.
+func newStringCache[T comparable](mode CacheMode, size int) *stringCache[T] {
+	if mode == CacheDisabled {
+		return nil
+	}
+
+	if size == 0 {
+		size = cache.DefaultMaxCacheSize
+	}
+
+	c := &stringCache[T]{cache: cache.New[T](size)}
+	if mode == CacheHashed {
+		c.hasher = newHasher(rand.Text())
+	}
+
+	return c
+}
+
+// key returns the cache key of the given string.
+func (c *stringCache[T]) key(s string) string {
+	if c.hasher == nil {
+		return s
+	}
+
+	sum := c.hasher.digest(s)
+
+	return string(sum[:])
+}
+
+// Get returns the value for the given string.
+// If the string is not cached, the second return value is false.
+func (c *stringCache[T]) Get(s string) (T, bool) {
+	if c == nil {
+		var zero T
+
+		return zero, false
+	}
+
+	return c.cache.Get(c.key(s))
+}
+
+// Set stores the value for the given string.
+func (c *stringCache[T]) Set(s string, value T) {
+	if c != nil {
+		c.cache.Set(c.key(s), value)
+	}
+}
+
+// Delete removes the given string from the cache.
+func (c *stringCache[T]) Delete(s string) {
+	if c != nil {
+		c.cache.Delete(c.key(s))
+	}
+}
+
+// Reset removes all the values from the cache.
+func (c *stringCache[T]) Reset() {
+	if c != nil {
+		c.cache.Reset()
+	}
+}
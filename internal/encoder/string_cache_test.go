@@ -0,0 +1,134 @@
+package encoder
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringCache(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		// GIVEN.
+		c := newStringCache[string](CachePlain, 0)
+
+		// WHEN.
+		c.Set("secret", "value")
+
+		// THEN.
+		got, ok := c.Get("secret")
+		require.True(t, ok)
+		require.Equal(t, "value", got)
+		_, ok = c.cache.Get("secret")
+		require.True(t, ok)
+	})
+
+	t.Run("hashed", func(t *testing.T) {
+		// GIVEN.
+		c := newStringCache[string](CacheHashed, 0)
+
+		// WHEN.
+		c.Set("secret", "value")
+
+		// THEN.
+		got, ok := c.Get("secret")
+		require.True(t, ok)
+		require.Equal(t, "value", got)
+		_, ok = c.cache.Get("secret")
+		require.False(t, ok)
+		_, ok = c.Get("other")
+		require.False(t, ok)
+
+		c.Delete("secret")
+		_, ok = c.Get("secret")
+		require.False(t, ok)
+	})
+
+	t.Run("hashed caches have different keys", func(t *testing.T) {
+		require.NotEqual(t, newStringCache[string](CacheHashed, 0).key("secret"), newStringCache[string](CacheHashed, 0).key("secret"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		// GIVEN.
+		c := newStringCache[string](CacheDisabled, 0)
+
+		// WHEN.
+		c.Set("secret", "value")
+		c.Delete("secret")
+		c.Reset()
+
+		// THEN.
+		require.Nil(t, c)
+		_, ok := c.Get("secret")
+		require.False(t, ok)
+	})
+
+	t.Run("size", func(t *testing.T) {
+		// GIVEN.
+		c := newStringCache[string](CacheHashed, 1)
+
+		// WHEN.
+		c.Set("a", "1")
+		c.Set("b", "2")
+
+		// THEN.
+		_, ok := c.Get("a")
+		require.False(t, ok)
+		got, ok := c.Get("b")
+		require.True(t, ok)
+		require.Equal(t, "2", got)
+	})
+}
+
+func TestEncoder_CacheMode(t *testing.T) {
+	type payload struct {
+		Note string            `censor:"display"`
+		Meta map[string]string `censor:"display"`
+	}
+
+	val := payload{Note: "card 4111", Meta: map[string]string{"password": "hunter2"}}
+
+	for name, mode := range map[string]CacheMode{"plain": CachePlain, "hashed": CacheHashed, "disabled": CacheDisabled} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{
+				MaskValue:       "[CENSORED]",
+				ExcludePatterns: []string{`\d{4}`},
+				MaskMapKeys:     []string{"pass"},
+				CacheMode:       mode,
+				CacheSize:       10,
+			}
+
+			t.Run("json", func(t *testing.T) {
+				// GIVEN.
+				e := NewJSONEncoder(cfg)
+
+				// WHEN.
+				// The value is encoded twice to cover the cached results.
+				for range 2 {
+					var b bytes.Buffer
+					e.Encode(NewBuffer(&b), reflect.ValueOf(val))
+
+					// THEN.
+					require.Equal(t, `{"Note": "card [CENSORED]","Meta": {"password":"[CENSORED]"}}`, b.String())
+				}
+				_, ok := e.maskMapKeys.matches.Get("password")
+				require.Equal(t, mode != CacheDisabled, ok)
+			})
+
+			t.Run("text", func(t *testing.T) {
+				// GIVEN.
+				e := NewTextEncoder(cfg)
+
+				// WHEN.
+				for range 2 {
+					var b bytes.Buffer
+					e.Encode(NewBuffer(&b), reflect.ValueOf(val))
+
+					// THEN.
+					require.Equal(t, `{Note: card [CENSORED], Meta: map[string]string{password: [CENSORED]}}`, b.String())
+				}
+			})
+		})
+	}
+}
@@ -1,16 +1,12 @@
 package encoder
 
 import (
-	"bytes"
 	"encoding"
 	"reflect"
-	"strconv"
-
-	"github.com/shopspring/decimal"
-
-	"github.com/vpakhuchyi/censor/internal/cache"
 )
 
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
 // TextEncoder is a struct that contains options for parsing.
 type TextEncoder struct {
 	baseEncoder
@@ -24,22 +20,42 @@ type TextEncoder struct {
 	// A struct name includes the last part of the package path.
 	// The default value is false.
 	DisplayStructName bool
+
+	// raw is used to embed json.RawMessage values as compact JSON.
+	raw *JSONEncoder
 }
 
 // NewTextEncoder returns a new instance of TextEncoder with given configuration.
 func NewTextEncoder(c Config) *TextEncoder {
 	p := TextEncoder{
 		baseEncoder: baseEncoder{
-			CensorFieldTag:    defaultCensorFieldTag,
-			ExcludePatterns:   c.ExcludePatterns,
-			MaskValue:         c.MaskValue,
-			UseJSONTagName:    c.UseJSONTagName,
-			structFieldsCache: cache.NewTypeCache[[]Field](cache.DefaultMaxCacheSize),
-			regexpCache:       cache.New[string](cache.DefaultMaxCacheSize),
+			CensorFieldTag:      defaultCensorFieldTag,
+			ExcludePatterns:     c.ExcludePatterns,
+			MaskValue:           c.MaskValue,
+			UseJSONTagName:      c.UseJSONTagName,
+			HashExcludePatterns: c.HashExcludePatterns,
+			DefaultFieldAction:  c.DefaultFieldAction,
+			fieldRules:          newFieldRules(c.FieldRules),
+			maskMapKeys:         newMapKeys(c.MaskMapKeys, c.CacheMode, c.CacheSize),
+			displayMapKeys:      newMapKeys(c.DisplayMapKeys, c.CacheMode, c.CacheSize),
+			DenyByDefault:       c.DenyByDefault,
+			BytesFormat:         c.BytesFormat,
+			detectors:           newDetectors(c),
+			MaxDepth:            c.MaxDepth,
+			MaxSliceLength:      c.MaxSliceLength,
+			MaxMapEntries:       c.MaxMapEntries,
+			MaxStringLength:     c.MaxStringLength,
+			MaxOutputBytes:      c.MaxOutputBytes,
+			hasher:              newHasher(c.HashKey),
+			regexpCache:         newStringCache[string](stringsCacheMode(c), c.CacheSize),
+			onMask:              c.OnMask,
+			inspect:             c.Inspect,
+			audited:             c.OnMask != nil || c.Inspect,
 		},
 		DisplayMapType:       c.DisplayMapType,
 		DisplayPointerSymbol: c.DisplayPointerSymbol,
 		DisplayStructName:    c.DisplayStructName,
+		raw:                  NewJSONEncoder(c),
 	}
 	if len(p.ExcludePatterns) != 0 {
 		p.ExcludePatternsCompiled = compileRegexpPatterns(p.ExcludePatterns)
@@ -48,125 +64,281 @@ func NewTextEncoder(c Config) *TextEncoder {
 	return &p
 }
 
-//nolint:exhaustive,gocyclo
-func (e *TextEncoder) Encode(b *bytes.Buffer, f reflect.Value) {
+// RegisterSecrets registers literal values that are masked in every encoded string, including the ones
+// of embedded json.RawMessage values. See baseEncoder.RegisterSecrets for details.
+func (e *TextEncoder) RegisterSecrets(values ...string) {
+	e.baseEncoder.RegisterSecrets(values...)
+	e.raw.RegisterSecrets(values...)
+}
+
+// Encode encodes the given value. Functions registered with RegisterType take precedence over the default rules.
+// Values that implement the censor.Censorer interface are replaced with the value returned by CensorValue.
+func (e *TextEncoder) Encode(b *Buffer, f reflect.Value) {
+	b.flush()
+
 	if !f.IsValid() {
 		b.WriteString("nil")
 
 		return
 	}
 
-	switch k := f.Kind(); k {
-	case reflect.Struct:
-		// If a field implements encoding.TextMarshaler interface, then it should be marshaled to string.
-		if v, ok := f.Interface().(encoding.TextMarshaler); ok {
-			b.WriteString(PrepareTextMarshalerValue(v))
-		} else {
-			e.Struct(b, f)
+	e.plan(f.Type()).encode(b, f)
+}
+
+// plan returns a compiled plan for the given type.
+func (e *TextEncoder) plan(t reflect.Type) *typePlan {
+	return e.plans.get(t, e.compile)
+}
+
+// compile builds a plan for the given type.
+func (e *TextEncoder) compile(t reflect.Type) typePlan {
+	kind := e.compileKind(t)
+	if e.DenyByDefault && isLoose(t.Kind()) {
+		kind = denyLoose(kind, e.reportedMask(t, ReasonDenyByDefault, e.writeMask))
+	}
+	if isLoose(t.Kind()) || isBytes(t) && e.BytesFormat != BytesNumbers {
+		kind = e.inspected(t, kind)
+	}
+
+	next := kind
+	if t.Kind() == reflect.Struct && t.Implements(textMarshalerType) {
+		// If a struct implements encoding.TextMarshaler interface, then it should be marshaled to string.
+		// The output is censored as any other string.
+		next = func(b *Buffer, v reflect.Value) {
+			if v.CanInterface() {
+				if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+					e.WriteString(b, PrepareTextMarshalerValue(m))
+
+					return
+				}
+			}
+
+			kind(b, v)
 		}
+		next = e.inspected(t, next)
+	}
+
+	return typePlan{
+		encode: e.withCustomRules(t, next, e.Encode),
+		kind:   kind,
+	}
+}
+
+//nolint:exhaustive
+func (e *TextEncoder) compileKind(t reflect.Type) encoderFunc {
+	switch t.Kind() {
+	case reflect.Struct:
+		return e.compileStruct(t)
 	case reflect.Slice, reflect.Array:
-		e.Slice(b, f)
+		if enc := e.compileBytes(t); enc != nil {
+			return enc
+		}
+
+		return e.compileSlice(t)
 	case reflect.Pointer:
-		e.Ptr(b, f)
+		return e.compilePtr(t)
 	case reflect.Map:
-		e.Map(b, f)
+		return e.compileMap(t)
 	case reflect.Interface:
-		e.Interface(b, f)
-	case reflect.Bool:
-		b.WriteString(strconv.FormatBool(f.Bool()))
+		return e.Interface
 	case reflect.String:
-		e.String(b, f.String())
-	case reflect.Float32:
-		b.WriteString(decimal.NewFromFloat32(float32(f.Float())).String())
-	case reflect.Float64:
-		b.WriteString(decimal.NewFromFloat(f.Float()).String())
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		b.WriteString(strconv.FormatInt(f.Int(), 10))
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		b.WriteString(strconv.FormatUint(f.Uint(), 10))
-	default:
-		b.WriteString(unsupportedTypeTmpl + k.String())
+		return func(b *Buffer, v reflect.Value) {
+			if s := v.String(); e.isLongString(s) {
+				e.writeTruncatedString(b, s)
+			} else {
+				e.String(b, s)
+			}
+		}
+	}
+
+	if enc := scalarEncoder(t.Kind()); enc != nil {
+		return enc
+	}
+
+	unsupported := unsupportedTypeTmpl + t.Kind().String()
+
+	return func(b *Buffer, _ reflect.Value) {
+		e.report(b, "", ReasonUnsupportedType, "", t)
+		b.WriteString(unsupported)
 	}
 }
 
 // Struct encodes a struct value to TEXT format.
-//
-//nolint:gocyclo,gocognit
-func (e *TextEncoder) Struct(b *bytes.Buffer, v reflect.Value) {
+// Note: this method panics if the provided value is not a struct.
+func (e *TextEncoder) Struct(b *Buffer, v reflect.Value) {
 	if v.Kind() != reflect.Struct {
 		panic("provided value is not a struct")
 	}
 
-	t := v.Type()
-
-	structPath := v.Type().PkgPath()
-	structName := t.Name()
+	e.plan(v.Type()).kind(b, v)
+}
 
-	var fields []Field
-	// Check if this is an anonymous struct (no package path)
-	if structPath == "" {
-		// Anonymous structs are not cached
-		fields = e.getStructFields(v, t)
-	} else {
-		// Try to get from cache using Type directly (no string allocation)
-		var found bool
-		fields, found = e.structFieldsCache.Get(t)
-		if !found {
-			fields = e.getStructFields(v, t)
-			e.structFieldsCache.Set(t, fields)
+// compileStruct returns an encoder function for structs of the given type.
+// Fields are parsed and plans of displayed fields are resolved only once.
+//
+//nolint:gocognit,gocyclo
+func (e *TextEncoder) compileStruct(t reflect.Type) encoderFunc {
+	fields := e.getStructFields(t)
+	plans := make([]*typePlan, len(fields))
+	for i, field := range fields {
+		if field.Name != "" && field.Action == ActionDisplay {
+			plans[i] = e.plan(t.Field(i).Type)
 		}
 	}
 
+	var structName string
 	if e.DisplayStructName {
-		var pkg string
-		// This custom logic is used instead of strings.Split to avoid unnecessary allocations.
-		for i := len(structPath) - 1; i >= 0; i-- {
-			// We iterate over the package path in reverse order until we find the last slash,
-			// which separates the package name from the package path.
-			if structPath[i] == '/' {
-				pkg = structPath[i+1:]
+		structName = textStructName(t)
+	}
+	audit := e.newStructAudit(t)
 
-				break
+	return func(b *Buffer, v reflect.Value) {
+		if !b.descend(e.MaxDepth) {
+			b.WriteString(truncationMarker)
+
+			return
+		}
+		defer b.ascend()
+		defer b.popPath(audit.enter(&e.baseEncoder, b))
+
+		b.WriteString(structName)
+		b.WriteString("{")
+
+		var written int
+		for i, field := range fields {
+			if field.Name == "" {
+				continue
+			}
+
+			if field.Action == ActionOmit {
+				audit.report(&e.baseEncoder, b, v, i)
+
+				continue
+			}
+
+			if written != 0 {
+				b.WriteByte(',')
+				b.WriteByte(' ')
 			}
-			// If there is no slash in the package path, then the package name is equal to the package path.
-			// Example: "main" package.
-			if i == 0 {
-				pkg = structPath[i:]
+
+			if e.truncate(b, written, 0) {
+				b.WriteString(moreMarker(countFields(fields[i:])))
 
 				break
 			}
+			written++
+
+			b.WriteString(field.Name)
+
+			if field.Action != ActionDisplay {
+				audit.report(&e.baseEncoder, b, v, i)
+			}
+
+			switch field.Action {
+			case ActionDisplay:
+				b.flush()
+				shown, n := b.show(), audit.push(&e.baseEncoder, b, i)
+				plans[i].encode(b, v.Field(i))
+				b.shown = shown
+				b.popPath(n)
+			case ActionMask:
+				b.WriteString(e.MaskValue)
+			case ActionHash:
+				e.writeHashed(b, v.Field(i))
+			default:
+				e.writePartiallyMasked(b, field, v.Field(i))
+			}
 		}
 
-		b.WriteString(pkg + "." + structName)
+		b.WriteByte('}')
 	}
+}
 
-	b.WriteString("{")
+// writeMask writes the mask value.
+func (e *TextEncoder) writeMask(b *Buffer) {
+	b.WriteString(e.MaskValue)
+}
 
-	for i := 0; i < len(fields); i++ {
-		b.WriteString(fields[i].Name)
+// textStructName returns a struct name that includes the last part of the package path, e.g. "encoder.Field".
+func textStructName(t reflect.Type) string {
+	structPath := t.PkgPath()
 
-		if fields[i].IsMasked {
-			b.WriteString(e.MaskValue)
-		} else {
-			e.Encode(b, v.Field(i))
+	var pkg string
+	// This custom logic is used instead of strings.Split to avoid unnecessary allocations.
+	for i := len(structPath) - 1; i >= 0; i-- {
+		// We iterate over the package path in reverse order until we find the last slash,
+		// which separates the package name from the package path.
+		if structPath[i] == '/' {
+			pkg = structPath[i+1:]
+
+			break
 		}
+		// If there is no slash in the package path, then the package name is equal to the package path.
+		// Example: "main" package.
+		if i == 0 {
+			pkg = structPath[i:]
 
-		if i < len(fields)-1 {
-			b.WriteByte(',')
-			b.WriteByte(' ')
+			break
 		}
 	}
 
-	b.WriteByte('}')
+	return pkg + "." + t.Name()
+}
+
+// writeTruncatedString writes a string that exceeds MaxStringLength
+// followed by a marker that says how many characters were cut off.
+func (e *TextEncoder) writeTruncatedString(b *Buffer, s string) {
+	truncated, n := e.censorAndTruncate(b, s)
+
+	b.WriteString(truncated)
+	if n > 0 {
+		b.WriteString(moreMarker(n))
+	}
+}
+
+// writeHashed writes a digest of the given value.
+// If the value can't be hashed, it's masked entirely.
+func (e *TextEncoder) writeHashed(b *Buffer, v reflect.Value) {
+	digest, ok := e.hashedValue(v)
+	if !ok {
+		b.WriteString(e.MaskValue)
+
+		return
+	}
+
+	b.WriteString(digest)
+}
+
+// writePartiallyMasked writes a value masked with one of the partial masking actions.
+// The visible part is still checked against the configured exclusion patterns.
+// If the value can't be partially masked, it's masked entirely.
+func (e *TextEncoder) writePartiallyMasked(b *Buffer, f Field, v reflect.Value) {
+	visible, maskFirst, ok := partialValue(f, v)
+	if !ok {
+		b.WriteString(e.MaskValue)
+
+		return
+	}
+
+	if maskFirst {
+		b.WriteString(e.MaskValue)
+		e.WriteString(b, visible)
+	} else {
+		e.WriteString(b, visible)
+		b.WriteString(e.MaskValue)
+	}
 }
 
-func (e *TextEncoder) getStructFields(v reflect.Value, t reflect.Type) []Field {
-	var fields []Field
+// getStructFields returns a slice of fields aligned with the struct field indexes.
+// Unexported fields are left with an empty name and are skipped during encoding.
+func (e *TextEncoder) getStructFields(t reflect.Type) []Field {
+	fields := make([]Field, t.NumField())
+	typeName := TypeName(t)
 	var name string
-	for i := 0; i < v.NumField(); i++ {
+	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
-		if !v.Field(i).CanInterface() {
+		if !field.IsExported() {
 			continue
 		}
 
@@ -176,10 +348,12 @@ func (e *TextEncoder) getStructFields(v reflect.Value, t reflect.Type) []Field {
 			name = field.Name
 		}
 
-		fields = append(fields, Field{
-			Name:     name + `: `,
-			IsMasked: field.Tag.Get(e.CensorFieldTag) != display,
-		})
+		action, keep := e.FieldAction(typeName, field.Name, field.Tag.Get(e.CensorFieldTag))
+		fields[i] = Field{
+			Name:   name + `: `,
+			Action: action,
+			Keep:   keep,
+		}
 	}
 
 	return fields
@@ -187,59 +361,153 @@ func (e *TextEncoder) getStructFields(v reflect.Value, t reflect.Type) []Field {
 
 // Map encodes a map value to TEXT format.
 // Note: this method panics if the provided value is not a map.
-func (e *TextEncoder) Map(b *bytes.Buffer, rv reflect.Value) {
+func (e *TextEncoder) Map(b *Buffer, rv reflect.Value) {
 	if rv.Kind() != reflect.Map {
 		panic("provided value is not a map")
 	}
 
-	b.WriteString(rv.Type().String())
-	b.WriteByte('{')
-	var addComma bool
-	for iter := rv.MapRange(); iter.Next(); {
-		if addComma {
-			b.WriteByte(',')
+	e.plan(rv.Type()).kind(b, rv)
+}
+
+// compileMap returns an encoder function for maps of the given type.
+// Values of keys matched by the MaskMapKeys patterns are masked. In the deny-by-default mode,
+// only values of keys matched by the DisplayMapKeys patterns are displayed.
+//
+//nolint:gocognit
+func (e *TextEncoder) compileMap(t reflect.Type) encoderFunc {
+	key, elem := e.compileMapKey(t.Key()), e.plan(t.Elem())
+	typeName := t.String()
+	checkKeys := e.maskMapKeys.checks(t.Key())
+	allowKeys := e.displayMapKeys.checks(t.Key())
+
+	return func(b *Buffer, rv reflect.Value) {
+		if !rv.IsNil() {
+			if !b.enter(rv) {
+				b.WriteString(textCycleMarker)
+
+				return
+			}
+			defer b.leave()
+		}
+
+		if !b.descend(e.MaxDepth) {
+			b.WriteString(truncationMarker)
+
+			return
+		}
+		defer b.ascend()
+
+		b.WriteString(typeName)
+		b.WriteByte('{')
+		var i int
+		for iter := rv.MapRange(); iter.Next(); i++ {
+			if i != 0 {
+				b.WriteByte(',')
+				b.WriteByte(' ')
+			}
+
+			if e.truncate(b, i, e.MaxMapEntries) {
+				b.WriteString(moreMarker(rv.Len() - i))
+
+				break
+			}
+
+			b.flush()
+			// Keys are displayed the same way as struct field names.
+			shown := b.show()
+			key(b, iter.Key())
+			b.WriteByte(':')
 			b.WriteByte(' ')
+			if reason := e.maskMapValue(iter.Key(), checkKeys, allowKeys); reason != "" {
+				e.report(b, "[]", reason, "", t.Elem())
+				b.WriteString(e.MaskValue)
+			} else {
+				n := e.pushPath(b, "[]")
+				elem.encode(b, iter.Value())
+				b.popPath(n)
+			}
+			b.shown = shown
 		}
 
-		key, value := iter.Key(), iter.Value()
+		b.WriteByte('}')
+	}
+}
 
-		e.Encode(b, key)
-		b.WriteByte(':')
-		b.WriteByte(' ')
-		e.Encode(b, value)
-		addComma = true
+// compileMapKey returns an encoder function for map keys of the given type. String keys are censored,
+// but never truncated, so keys with a common prefix stay distinct.
+func (e *TextEncoder) compileMapKey(t reflect.Type) encoderFunc {
+	if t.Kind() != reflect.String {
+		return e.plan(t).encode
 	}
 
-	b.WriteByte('}')
+	return func(b *Buffer, v reflect.Value) {
+		e.String(b, v.String())
+	}
 }
 
 // Slice encodes a slice value to TEXT format.
 // This function is also can be used to parse an array.
 // Note: this method panics if the provided value is not a slice or array.
-func (e *TextEncoder) Slice(b *bytes.Buffer, rv reflect.Value) {
+func (e *TextEncoder) Slice(b *Buffer, rv reflect.Value) {
 	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
 		panic("provided value is not a slice/array")
 	}
 
-	b.WriteByte('[')
+	e.plan(rv.Type()).kind(b, rv)
+}
+
+// compileSlice returns an encoder function for slices or arrays of the given type.
+func (e *TextEncoder) compileSlice(t reflect.Type) encoderFunc {
+	elem := e.plan(t.Elem())
+	isSlice := t.Kind() == reflect.Slice
 
-	for i := 0; i < rv.Len(); i++ {
-		e.Encode(b, rv.Index(i))
+	return func(b *Buffer, rv reflect.Value) {
+		if isSlice && !rv.IsNil() {
+			if !b.enter(rv) {
+				b.WriteString(textCycleMarker)
 
-		if i < rv.Len()-1 {
-			b.WriteByte(',')
-			b.WriteByte(' ')
+				return
+			}
+			defer b.leave()
 		}
-	}
 
-	b.WriteByte(']')
+		if !b.descend(e.MaxDepth) {
+			b.WriteString(truncationMarker)
+
+			return
+		}
+		defer b.ascend()
+
+		b.WriteByte('[')
+
+		length := rv.Len()
+		for i := 0; i < length; i++ {
+			if i != 0 {
+				b.WriteByte(',')
+				b.WriteByte(' ')
+			}
+
+			if e.truncate(b, i, e.MaxSliceLength) {
+				b.WriteString(moreMarker(length - i))
+
+				break
+			}
+
+			b.flush()
+			n := e.pushPath(b, "[]")
+			elem.encode(b, rv.Index(i))
+			b.popPath(n)
+		}
+
+		b.WriteByte(']')
+	}
 }
 
 // Interface encodes an interface value to TEXT format.
 // In case of a pointer to unsupported type of value, a string built from unsupportedTypeTmpl
 // is used instead of the real value. That string contains a type of the value.
 // Note: this method panics if the provided value is not an interface.
-func (e *TextEncoder) Interface(b *bytes.Buffer, rv reflect.Value) {
+func (e *TextEncoder) Interface(b *Buffer, rv reflect.Value) {
 	if rv.Kind() != reflect.Interface {
 		panic("provided value is not an interface")
 	}
@@ -255,26 +523,43 @@ func (e *TextEncoder) Interface(b *bytes.Buffer, rv reflect.Value) {
 // Ptr encodes a pointer value to TEXT format.
 // In case of a pointer to unsupported type of value, a string built from unsupportedTypeTmpl
 // is used instead of the real value. That string contains a type of the value.
+// A pointer that refers to a value that is being encoded is replaced with textCycleMarker.
 // Note: this method panics if the provided value is not a pointer.
-func (e *TextEncoder) Ptr(b *bytes.Buffer, rv reflect.Value) {
+func (e *TextEncoder) Ptr(b *Buffer, rv reflect.Value) {
 	if rv.Kind() != reflect.Pointer {
 		panic("provided value is not a pointer")
 	}
 
-	if rv.IsNil() {
-		b.WriteString("nil")
+	e.plan(rv.Type()).kind(b, rv)
+}
 
-		return
-	}
+// compilePtr returns an encoder function for pointers of the given type.
+func (e *TextEncoder) compilePtr(t reflect.Type) encoderFunc {
+	elem := e.plan(t.Elem())
 
-	if e.DisplayPointerSymbol {
-		b.WriteByte('&')
+	return func(b *Buffer, rv reflect.Value) {
+		if rv.IsNil() {
+			b.WriteString("nil")
+
+			return
+		}
+
+		if !b.enter(rv) {
+			b.WriteString(textCycleMarker)
+
+			return
+		}
+
+		if e.DisplayPointerSymbol {
+			b.WriteByte('&')
+		}
+		elem.encode(b, rv.Elem())
+		b.leave()
 	}
-	e.Encode(b, rv.Elem())
 }
 
 // String formats a value as a string.
 // If the string matches one of the ExcludePatterns, it will be masked with the MaskValue.
-func (e *TextEncoder) String(b *bytes.Buffer, s string) {
+func (e *TextEncoder) String(b *Buffer, s string) {
 	e.WriteString(b, s)
 }
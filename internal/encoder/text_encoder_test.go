@@ -8,19 +8,17 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
-
-	"github.com/vpakhuchyi/censor/internal/cache"
 )
 
 func TestTextEncoder_NewTextEncoder(t *testing.T) {
 	got := NewTextEncoder(Config{UseJSONTagName: true})
 	exp := &TextEncoder{
 		baseEncoder: baseEncoder{
-			CensorFieldTag:    defaultCensorFieldTag,
-			UseJSONTagName:    true,
-			structFieldsCache: cache.NewTypeCache[[]Field](cache.DefaultMaxCacheSize),
-			regexpCache:       cache.New[string](cache.DefaultMaxCacheSize),
+			CensorFieldTag: defaultCensorFieldTag,
+			UseJSONTagName: true,
+			regexpCache:    newStringCache[string](CachePlain, 0),
 		},
+		raw: NewJSONEncoder(Config{UseJSONTagName: true}),
 	}
 	require.EqualValues(t, exp, got)
 }
@@ -112,7 +110,7 @@ func TestTextEncoder_Encode(t *testing.T) {
 	defer b.Reset()
 
 	// WHEN.
-	e.Encode(&b, reflect.ValueOf(p))
+	e.Encode(NewBuffer(&b), reflect.ValueOf(p))
 
 	// THEN.
 	exp := `encoder.payload{` +
@@ -141,7 +139,7 @@ func TestTextEncoder_Struct(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Struct(&b, reflect.ValueOf(v))
+			e.Struct(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -163,7 +161,7 @@ func TestTextEncoder_Struct(t *testing.T) {
 			}{}
 
 			// WHEN.
-			e.Struct(&b, reflect.ValueOf(s))
+			e.Struct(NewBuffer(&b), reflect.ValueOf(s))
 
 			// THEN.
 			exp := "{}"
@@ -182,7 +180,7 @@ func TestTextEncoder_Map(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -202,7 +200,7 @@ func TestTextEncoder_Map(t *testing.T) {
 			}
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -222,7 +220,7 @@ func TestTextEncoder_Map(t *testing.T) {
 			}
 
 			// WHEN.
-			e.Map(&b, reflect.ValueOf(v))
+			e.Map(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			got := b.String()
@@ -242,7 +240,7 @@ func TestTextEncoder_Slice(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Slice(&b, reflect.ValueOf(v))
+			e.Slice(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -260,7 +258,7 @@ func TestTextEncoder_Interface(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Interface(&b, reflect.ValueOf(v))
+			e.Interface(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -278,7 +276,7 @@ func TestTextEncoder_Ptr(t *testing.T) {
 			v := 26
 
 			// WHEN.
-			e.Ptr(&b, reflect.ValueOf(v))
+			e.Ptr(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
@@ -294,10 +292,122 @@ func TestTextEncoder_Ptr(t *testing.T) {
 			var v *string
 
 			// WHEN.
-			e.Ptr(&b, reflect.ValueOf(v))
+			e.Ptr(NewBuffer(&b), reflect.ValueOf(v))
 
 			// THEN.
 			// Panic.
 		})
 	})
 }
+
+func TestTextEncoder_Struct_PartialMasking(t *testing.T) {
+	// GIVEN.
+	type payload struct {
+		Card  string `censor:"mask=last4"`
+		Name  string `censor:"mask=first2"`
+		Email string `censor:"mask=email"`
+		Phone int64  `censor:"mask=last2"`
+		Short string `censor:"mask=last4"`
+	}
+
+	e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+	var b bytes.Buffer
+	defer b.Reset()
+
+	value := payload{
+		Card:  "4111111111111234",
+		Name:  "Ivan",
+		Email: "john.doe@example.com",
+		Phone: 380501234567,
+		Short: "1234",
+	}
+
+	// WHEN.
+	e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+	// THEN.
+	exp := `{Card: [CENSORED]1234, Name: Iv[CENSORED], Email: [CENSORED]@example.com, Phone: [CENSORED]67, Short: [CENSORED]}`
+	require.Equal(t, exp, b.String())
+}
+
+func TestTextEncoder_Struct_UnexportedFields(t *testing.T) {
+	// GIVEN.
+	type payload struct {
+		Public  string `censor:"display"`
+		private string
+		Last    string `censor:"display"`
+	}
+
+	e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+	var b bytes.Buffer
+	defer b.Reset()
+
+	// WHEN.
+	e.Struct(NewBuffer(&b), reflect.ValueOf(payload{Public: "public", private: "private", Last: "last"}))
+
+	// THEN.
+	require.Equal(t, `{Public: public, Last: last}`, b.String())
+}
+
+func TestTextEncoder_Hashing(t *testing.T) {
+	// GIVEN.
+	type payload struct {
+		UserID int64  `censor:"hash"`
+		Email  string `censor:"hash"`
+		Note   string `censor:"display"`
+	}
+
+	e := NewTextEncoder(Config{
+		MaskValue:           "[CENSORED]",
+		HashKey:             "secret",
+		HashExcludePatterns: true,
+		ExcludePatterns:     []string{`\S+@\S+`},
+	})
+	var b bytes.Buffer
+	defer b.Reset()
+	h := newHasher("secret")
+
+	// WHEN.
+	e.Struct(NewBuffer(&b), reflect.ValueOf(payload{UserID: 42, Email: "user@example.com", Note: "mail user@example.com"}))
+
+	// THEN.
+	exp := `{UserID: ` + h.Sum("42") + `, Email: ` + h.Sum("user@example.com") + `, Note: mail ` + h.Sum("user@example.com") + `}`
+	require.Equal(t, exp, b.String())
+}
+
+func TestTextEncoder_Struct_Omit(t *testing.T) {
+	type payload struct {
+		Password string `censor:"omit"`
+		ID       string `censor:"display"`
+		Email    string
+		Secret   string `censor:"omit"`
+	}
+
+	value := payload{Password: "pass", ID: "42", Email: "user@example.com", Secret: "secret"}
+
+	t.Run("omit tag", func(t *testing.T) {
+		// GIVEN.
+		e := NewTextEncoder(Config{MaskValue: "[CENSORED]"})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		require.Equal(t, `{ID: 42, Email: [CENSORED]}`, b.String())
+	})
+
+	t.Run("omit by default", func(t *testing.T) {
+		// GIVEN.
+		e := NewTextEncoder(Config{MaskValue: "[CENSORED]", DefaultFieldAction: ActionOmit})
+		var b bytes.Buffer
+		defer b.Reset()
+
+		// WHEN.
+		e.Struct(NewBuffer(&b), reflect.ValueOf(value))
+
+		// THEN.
+		require.Equal(t, `{ID: 42}`, b.String())
+	})
+}
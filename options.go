@@ -4,6 +4,8 @@ package censor
 type OptsConfig struct {
 	config     *Config
 	configPath string
+	hashKey    string
+	onMask     func(MaskEvent)
 }
 
 // Option is a function that sets some option on the Processor.
@@ -22,3 +24,22 @@ func WithConfigPath(path string) func(*OptsConfig) {
 		o.configPath = path
 	}
 }
+
+// WithHashKey returns an Option that sets the secret key used to compute digests of hashed values.
+// It overrides the hash key from the configuration.
+func WithHashKey(key string) func(*OptsConfig) {
+	return func(o *OptsConfig) {
+		o.hashKey = key
+	}
+}
+
+// WithOnMask returns an Option that sets a hook called for every value that is masked, hashed, partially masked
+// or omitted, e.g. to audit the configuration or to count masked values by reason. The hook is called
+// synchronously during encoding, so it must be fast and safe for concurrent use.
+// Without the hook, there is no overhead at all. With it, paths of values are tracked, censored strings
+// are not cached, and code generated with censorgen is not used.
+func WithOnMask(fn func(MaskEvent)) func(*OptsConfig) {
+	return func(o *OptsConfig) {
+		o.onMask = fn
+	}
+}
@@ -2,6 +2,7 @@ package censor
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 	"sync"
 
@@ -12,7 +13,16 @@ import (
 // Processor is responsible for data encoding according to the specified configuration.
 type Processor struct {
 	encoder encoder.Encoder
-	cfg     Config
+	// json is used by code generated with censorgen. It's the same as encoder if the JSON format is configured.
+	json *encoder.JSONEncoder
+	cfg  Config
+	// onMask is called for every masked value. It's nil if no hook is set with WithOnMask.
+	onMask func(MaskEvent)
+	// inspector holds the encoder used by Inspect.
+	inspector *inspector
+	// buf is set only for processors passed to generated code while a value is being encoded.
+	// Generated code writes to it, so the state of the encoding call is preserved.
+	buf *encoder.Buffer
 }
 
 // Censor pkg contains a global instance of Processor.
@@ -29,7 +39,7 @@ func New() *Processor {
 		panic(fmt.Sprintf("censor: invalid default configuration: %v", err))
 	}
 
-	return newProcessor(cfg)
+	return newProcessor(cfg, nil)
 }
 
 // NewWithOpts returns a new instance of Processor, options can be passed to it.
@@ -56,17 +66,32 @@ func NewWithOpts(opts ...Option) (*Processor, error) {
 		cfg = &c
 	}
 
+	if optCfg.hashKey != "" {
+		// The given configuration is copied, so the caller's value stays untouched.
+		c := *cfg
+		c.Encoder.HashKey = optCfg.hashKey
+		cfg = &c
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	secrets, err := cfg.Encoder.Secrets.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+
 	if cfg.General.PrintConfigOnInit {
 		// We want to print the configuration only if it differs from the default one
 		// and the corresponding flag is set to true.
 		fmt.Print(cfg.ToString())
 	}
 
-	return newProcessor(*cfg), nil
+	p := newProcessor(*cfg, optCfg.onMask)
+	p.RegisterSecrets(secrets...)
+
+	return p, nil
 }
 
 // SetGlobalInstance sets a given Processor as a global instance.
@@ -84,15 +109,28 @@ func GetGlobalInstance() *Processor {
 	return globalInstance
 }
 
-func newProcessor(cfg Config) *Processor {
+func newProcessor(cfg Config, onMask func(MaskEvent)) *Processor {
 	p := &Processor{
-		cfg: cfg,
+		cfg:       cfg,
+		onMask:    onMask,
+		inspector: new(inspector),
+	}
+
+	jsonCfg := cfg.Encoder.toEncoderConfig()
+	jsonCfg.OnMask = encoderHook(onMask, OutputFormatJSON)
+	p.json = encoder.NewJSONEncoder(jsonCfg)
+	if cfg.Encoder.MaxDepth == 0 && cfg.Encoder.MaxOutputBytes == 0 && onMask == nil {
+		// Generated code doesn't track the depth, the size of the output and the paths of values,
+		// so it's not used if these limits or the hook are set.
+		p.json.UseGenerated(jsonAppenderType, p.appendGenerated)
 	}
 
 	if cfg.General.OutputFormat == OutputFormatJSON {
-		p.encoder = encoder.NewJSONEncoder(cfg.Encoder.toEncoderConfig())
+		p.encoder = p.json
 	} else {
-		p.encoder = encoder.NewTextEncoder(cfg.Encoder.toEncoderConfig())
+		textCfg := cfg.Encoder.toEncoderConfig()
+		textCfg.OnMask = encoderHook(onMask, OutputFormatText)
+		p.encoder = encoder.NewTextEncoder(textCfg)
 	}
 
 	return p
@@ -120,15 +158,93 @@ func Any(val any) []byte {
 	return instance.Any(val)
 }
 
+// AppendAny appends a representation of the given value with sensitive data masked to dst
+// and returns the extended slice. See Processor.AppendAny for details.
+func AppendAny(dst []byte, val any) []byte {
+	globalInstanceMu.RLock()
+	instance := globalInstance
+	globalInstanceMu.RUnlock()
+
+	return instance.AppendAny(dst, val)
+}
+
 // Any returns a byte slice representation of the given value with sensitive data masked.
 // It behaves the same as the global Any function — recursively processing and masking values.
+// The returned slice is owned by the caller.
 func (p *Processor) Any(val any) []byte {
+	return p.AppendAny(nil, val)
+}
+
+// AppendAny appends a representation of the given value with sensitive data masked to dst
+// and returns the extended slice. Just like with the built-in append, dst must not be used after the call,
+// use the returned slice instead.
+//
+// The result is the same as the one returned by Any. AppendAny doesn't allocate memory for the output
+// if dst has enough capacity, so it's a better fit for hot paths, e.g. loggers that reuse their buffers.
+func (p *Processor) AppendAny(dst []byte, val any) []byte {
+	b := encoder.GetBuffer()
+	defer encoder.PutBuffer(b)
+
+	p.encoder.Encode(b, reflect.ValueOf(val))
+
+	return append(dst, b.Bytes()...)
+}
+
+// Fprint writes a representation of the given value with sensitive data masked to w.
+// Unlike Any, it doesn't keep the whole output in memory: it's written to w in chunks while the value is encoded.
+// It returns the number of bytes written and any write error encountered.
+func Fprint(w io.Writer, val any) (int64, error) {
+	globalInstanceMu.RLock()
+	instance := globalInstance
+	globalInstanceMu.RUnlock()
+
+	return instance.WriteTo(w, val)
+}
+
+// WriteTo writes a representation of the given value with sensitive data masked to w.
+// It behaves the same as the global Fprint function, using the Processor instance's configuration.
+//
+// The output is the same as the one returned by Any, but it's written to w in chunks while the value is encoded,
+// so large values never need to be kept in memory as a whole. If w returns an error, encoding continues
+// without writing, and the first error is returned.
+func (p *Processor) WriteTo(w io.Writer, val any) (int64, error) {
 	b := builderpool.Get()
 	defer builderpool.Put(b)
 
-	p.encoder.Encode(b, reflect.ValueOf(val))
+	buf := encoder.NewWriterBuffer(w, b)
+	p.encoder.Encode(buf, reflect.ValueOf(val))
 
-	return b.Bytes()
+	return buf.Flush()
+}
+
+// JSON parses the given JSON document and returns it with sensitive data masked.
+// See Processor.JSON for details.
+func JSON(raw []byte) ([]byte, error) {
+	globalInstanceMu.RLock()
+	instance := globalInstance
+	globalInstanceMu.RUnlock()
+
+	return instance.JSON(raw)
+}
+
+// JSON parses the given JSON document (e.g. a request body or a queue message) and returns it
+// with sensitive data masked. The output is always compact JSON, regardless of the configured output format.
+//
+// The document is processed token by token, without decoding it into a tree of values. Every string is checked
+// against the exclude patterns, and object members are processed the same way as map entries: values of keys
+// matched by MaskMapKeys are masked, and in the deny-by-default mode, only values of keys matched
+// by DisplayMapKeys stay visible. The configured limits are applied as well.
+//
+// An error is returned if raw is not a single valid JSON value.
+func (p *Processor) JSON(raw []byte) ([]byte, error) {
+	b := encoder.GetBuffer()
+	defer encoder.PutBuffer(b)
+
+	if err := p.json.RawJSON(b, raw); err != nil {
+		return nil, fmt.Errorf("failed to process the JSON document: %w", err)
+	}
+
+	return append([]byte(nil), b.Bytes()...), nil
 }
 
 // String processes the given string by validating it against the configured regular expressions.
@@ -142,16 +258,34 @@ func String(s string) []byte {
 	return instance.String(s)
 }
 
+// AppendString appends the processed version of the given string to dst and returns the extended slice.
+// See Processor.AppendString for details.
+func AppendString(dst []byte, s string) []byte {
+	globalInstanceMu.RLock()
+	instance := globalInstance
+	globalInstanceMu.RUnlock()
+
+	return instance.AppendString(dst, s)
+}
+
 // String returns a byte slice containing the processed version of the input string,
 // where segments matching the configured regular expressions are replaced with the mask value.
 // It behaves identically to the global String function, using the Processor instance's configuration.
+// The returned slice is owned by the caller.
 func (p *Processor) String(s string) []byte {
-	b := builderpool.Get()
-	defer builderpool.Put(b)
+	return p.AppendString(nil, s)
+}
+
+// AppendString appends the processed version of the given string to dst and returns the extended slice.
+// Just like with the built-in append, dst must not be used after the call, use the returned slice instead.
+// The result is the same as the one returned by String.
+func (p *Processor) AppendString(dst []byte, s string) []byte {
+	b := encoder.GetBuffer()
+	defer encoder.PutBuffer(b)
 
 	p.encoder.String(b, s)
 
-	return b.Bytes()
+	return append(dst, b.Bytes()...)
 }
 
 // OutputFormat returns the configured output format (OutputFormatJSON or OutputFormatText).
@@ -164,8 +298,83 @@ func (p *Processor) OutputFormat() string {
 }
 
 // Clone returns a new instance of Processor with the same configuration as the original one.
+// Functions registered with RegisterType, known secrets and the hook set with WithOnMask are copied
+// to the new instance as well.
 func (p *Processor) Clone() (*Processor, error) {
-	return NewWithOpts(WithConfig(&p.cfg))
+	clone, err := NewWithOpts(WithConfig(&p.cfg), WithOnMask(p.onMask))
+	if err != nil {
+		return nil, err
+	}
+
+	for t, fn := range p.encoder.RegisteredTypes() {
+		clone.RegisterType(t, fn)
+	}
+
+	clone.RegisterSecrets(p.encoder.RegisteredSecrets()...)
+
+	return clone, nil
+}
+
+// RegisterType registers a function that defines how values of the given type are encoded.
+// It's useful for types that can't be tagged, e.g. types from third-party packages.
+//
+// The function is called for every value of exactly the given type (a pointer type must be registered separately),
+// before any other rule is applied. The returned value is encoded using the regular rules: returned structs
+// are masked according to their tags and strings are checked against the exclude patterns.
+// If the function returns a value of the same type, it's encoded without calling the function again.
+// Nil pointers are never passed to the function.
+//
+// Registering a function for a type that already has one replaces it.
+// It's safe to call RegisterType concurrently with encoding.
+func (p *Processor) RegisterType(t reflect.Type, fn func(v reflect.Value) any) {
+	if t == nil || fn == nil {
+		panic("censor: type and function must not be nil")
+	}
+
+	p.encoder.RegisterType(t, fn)
+	if p.encoder != encoder.Encoder(p.json) {
+		p.json.RegisterType(t, fn)
+	}
+	p.inspector.register(func(e *encoder.JSONEncoder) { e.RegisterType(t, fn) })
+}
+
+// RegisterSecrets registers literal values, e.g. database passwords, API keys or signing secrets,
+// that must never appear in the output. Every exact occurrence of them in any encoded string is masked,
+// regardless of tags, exclude patterns and detectors. Empty values are ignored.
+//
+// Secrets are found with a multi-pattern matcher, so the number of secrets doesn't affect the speed of encoding
+// much. Strings that contain secrets are never cached, so the secrets are kept in memory only by the matcher.
+// It's safe to call RegisterSecrets concurrently with encoding.
+func (p *Processor) RegisterSecrets(values ...string) {
+	p.encoder.RegisterSecrets(values...)
+	if p.encoder != encoder.Encoder(p.json) {
+		p.json.RegisterSecrets(values...)
+	}
+	p.inspector.register(func(e *encoder.JSONEncoder) { e.RegisterSecrets(values...) })
+}
+
+// RegisterFunc is a type-safe version of Processor.RegisterType.
+// The given function is used to encode values of type T.
+//
+// Example:
+//
+//	censor.RegisterFunc(p, func(u url.URL) any {
+//		u.User = nil
+//		return u.String()
+//	})
+func RegisterFunc[T any](p *Processor, fn func(v T) any) {
+	if fn == nil {
+		panic("censor: function must not be nil")
+	}
+
+	p.RegisterType(reflect.TypeFor[T](), func(v reflect.Value) any {
+		val, ok := v.Interface().(T)
+		if !ok {
+			return nil
+		}
+
+		return fn(val)
+	})
 }
 
 const censorIsNotInitializedMsg = "censor is not initialized"